@@ -0,0 +1,160 @@
+package svg
+
+import (
+	"io"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// ARIA holds accessibility attributes for an element
+// Use Attrs to add them to an element's Attrs
+type ARIA struct {
+	Role        string // e.g. "img", "group", "list"
+	Label       string // Accessible name (aria-label)
+	LabelledBy  string // IDs of elements naming this one (aria-labelledby)
+	DescribedBy string // IDs of elements describing this one (aria-describedby)
+	Hidden      bool   // Hide the element from assistive technology
+}
+
+// Attrs returns the ARIA attributes that are set
+func (a ARIA) Attrs() []Attr {
+	var attrs []Attr
+	attrs = appendNonEmpty(attrs, "role", a.Role)
+	attrs = appendNonEmpty(attrs, "aria-label", a.Label)
+	attrs = appendNonEmpty(attrs, "aria-labelledby", a.LabelledBy)
+	attrs = appendNonEmpty(attrs, "aria-describedby", a.DescribedBy)
+	if a.Hidden {
+		attrs = append(attrs, Attr{"aria-hidden", "true"})
+	}
+	return attrs
+}
+
+// AccessibleName returns attributes that expose an element as an image
+// with the given name
+func AccessibleName(name string) []Attr {
+	return ARIA{Role: "img", Label: name}.Attrs()
+}
+
+// TitleElement represents an SVG <title> element
+// As the first child of an element it is that element's accessible name
+type TitleElement struct {
+	ID      string
+	Content string
+}
+
+// Tag returns the element name
+func (e *TitleElement) Tag() string { return "title" }
+
+// Attributes returns the title attributes
+func (e *TitleElement) Attributes() []Attr { return appendNonEmpty(nil, "id", e.ID) }
+
+// Children returns the title text
+func (e *TitleElement) Children() []Element { return []Element{CharData(e.Content)} }
+
+// WriteTo writes the title markup to w
+func (e *TitleElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// DescElement represents an SVG <desc> element
+type DescElement struct {
+	ID      string
+	Content string
+}
+
+// Tag returns the element name
+func (e *DescElement) Tag() string { return "desc" }
+
+// Attributes returns the desc attributes
+func (e *DescElement) Attributes() []Attr { return appendNonEmpty(nil, "id", e.ID) }
+
+// Children returns the description text
+func (e *DescElement) Children() []Element { return []Element{CharData(e.Content)} }
+
+// WriteTo writes the desc markup to w
+func (e *DescElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// DataTable is an accessible table fallback for a chart
+//
+// It is written as an invisible group with ARIA table roles, so screen
+// readers can navigate the underlying data while the chart is shown.
+type DataTable struct {
+	Caption string
+	Headers []string
+	Rows    [][]string
+}
+
+// Element returns the table as an invisible <g role="table">
+func (t DataTable) Element() Element {
+	table := &GroupElement{
+		Attrs: append(ARIA{Role: "table", Label: t.Caption}.Attrs(), Attr{"opacity", "0"}, Attr{"pointer-events", "none"}),
+	}
+	row := func(cells []string, role string) Element {
+		g := &GroupElement{Attrs: []Attr{{"role", "row"}}}
+		for _, cell := range cells {
+			g.Elements = append(g.Elements, &TextElement{Content: cell, Attrs: []Attr{{"role", role}}})
+		}
+		return g
+	}
+	if len(t.Headers) > 0 {
+		table.Elements = append(table.Elements, row(t.Headers, "columnheader"))
+	}
+	for _, cells := range t.Rows {
+		table.Elements = append(table.Elements, row(cells, "cell"))
+	}
+	return table
+}
+
+// rootAccessibility returns the ARIA attributes of the root <svg> element
+// and the <title> and <desc> elements written as its first children
+func (r *Renderer) rootAccessibility() ([]Attr, string) {
+	opts := r.options
+	if opts.Title == "" && opts.Description == "" && opts.DataTable == nil {
+		return nil, ""
+	}
+
+	aria := ARIA{Role: "img"}
+	if opts.DataTable != nil {
+		// Children of role="img" are hidden from assistive technology,
+		// which would hide the table too
+		aria.Role = "group"
+	}
+
+	var b strings.Builder
+	if opts.Title != "" {
+		title := &TitleElement{ID: r.ids.Next("title"), Content: opts.Title}
+		aria.LabelledBy = title.ID
+		b.WriteString(Serialize(title))
+		b.WriteString("\n")
+	}
+	if opts.Description != "" {
+		desc := &DescElement{ID: r.ids.Next("desc"), Content: opts.Description}
+		aria.DescribedBy = desc.ID
+		b.WriteString(Serialize(desc))
+		b.WriteString("\n")
+	}
+	return aria.Attrs(), b.String()
+}
+
+// dataTable returns the accessible data table, if any
+func (r *Renderer) dataTable() string {
+	if r.options.DataTable == nil {
+		return ""
+	}
+	return Serialize(r.options.DataTable.Element()) + "\n"
+}
+
+// nodeARIA returns the accessibility attributes for a node
+// Containers become named groups and leaf nodes named images
+func (r *Renderer) nodeARIA(node *layout.Node, depth int, container bool) []Attr {
+	if r.options.AccessibleNameFunc == nil {
+		return nil
+	}
+	name := r.options.AccessibleNameFunc(node, depth)
+	if name == "" {
+		return nil
+	}
+	if container {
+		return ARIA{Role: "group", Label: name}.Attrs()
+	}
+	return AccessibleName(name)
+}
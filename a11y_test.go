@@ -0,0 +1,103 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestRenderAccessibility(t *testing.T) {
+	leaf := &layout.Node{Rect: layout.Rect{Width: 10, Height: 10}}
+	root := &layout.Node{
+		Rect:     layout.Rect{Width: 100, Height: 50},
+		Children: []*layout.Node{leaf},
+	}
+
+	tests := []struct {
+		name     string
+		setup    func(*Options)
+		expected []string
+		excluded []string
+	}{
+		{
+			name:     "no accessibility options",
+			setup:    func(opts *Options) {},
+			excluded: []string{"<title", "role=", "aria-"},
+		},
+		{
+			name: "title and description",
+			setup: func(opts *Options) {
+				opts.Title = "Sales & returns"
+				opts.Description = "Monthly sales for 2025"
+			},
+			expected: []string{
+				`role="img" aria-labelledby="title-1" aria-describedby="desc-1">`,
+				"<title id=\"title-1\">Sales &amp; returns</title>",
+				`<desc id="desc-1">Monthly sales for 2025</desc>`,
+			},
+		},
+		{
+			name: "data table fallback",
+			setup: func(opts *Options) {
+				opts.Title = "Sales"
+				opts.DataTable = &DataTable{
+					Caption: "Sales by month",
+					Headers: []string{"Month", "Sales"},
+					Rows:    [][]string{{"Jan", "10"}, {"Feb", "12"}},
+				}
+			},
+			expected: []string{
+				`role="group" aria-labelledby="title-1">`,
+				`<g role="table" aria-label="Sales by month" opacity="0" pointer-events="none">`,
+				`<text x="0.00" y="0.00" role="columnheader">Month</text>`,
+				`role="cell">12</text>`,
+			},
+		},
+		{
+			name: "node accessible names",
+			setup: func(opts *Options) {
+				opts.AccessibleNameFunc = func(node *layout.Node, depth int) string {
+					if node == leaf {
+						return "Revenue bar"
+					}
+					return "Chart area"
+				}
+			},
+			expected: []string{
+				`<g role="group" aria-label="Chart area">`,
+				`<g role="img" aria-label="Revenue bar">`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tt.setup(&opts)
+
+			for _, svg := range []string{RenderToSVG(root, opts), RenderNodes([]*layout.Node{root}, opts)} {
+				for _, expected := range tt.expected {
+					if !strings.Contains(svg, expected) {
+						t.Errorf("Expected %q in:\n%s", expected, svg)
+					}
+				}
+				for _, excluded := range tt.excluded {
+					if strings.Contains(svg, excluded) {
+						t.Errorf("Did not expect %q in:\n%s", excluded, svg)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestARIAAttrs(t *testing.T) {
+	attrs := formatAttrs(ARIA{Role: "list", LabelledBy: "a b", Hidden: true}.Attrs())
+	if attrs != ` role="list" aria-labelledby="a b" aria-hidden="true"` {
+		t.Errorf("unexpected attributes: %s", attrs)
+	}
+	if got := Serialize(&RectElement{Width: 1, Height: 1, Attrs: AccessibleName("Bar")}); !strings.Contains(got, `role="img" aria-label="Bar"`) {
+		t.Errorf("unexpected element: %s", got)
+	}
+}
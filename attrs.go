@@ -0,0 +1,168 @@
+package svg
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// attrNamePattern matches attribute names that are safe to write,
+// optionally with a namespace prefix such as xlink:href
+var attrNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*(:[A-Za-z_][A-Za-z0-9_.-]*)?$`)
+
+// ID returns an id attribute
+func ID(id string) Attr {
+	return Attr{"id", id}
+}
+
+// Data returns a data-* attribute, e.g. Data("series", "revenue")
+// The name is lower-cased, as HTML and SVG require
+func Data(name, value string) Attr {
+	return Attr{"data-" + strings.ToLower(name), value}
+}
+
+// SafeAttrs returns the attributes that are safe to add to an element
+//
+// Attributes with invalid names, event handlers (onclick, ...), links to
+// script URLs and inline styles that can load script are dropped. Values are
+// always escaped when written, so they cannot break out of the attribute.
+func SafeAttrs(attrs ...Attr) []Attr {
+	var safe []Attr
+	for _, attr := range attrs {
+		if isSafeAttr(attr.Name, attr.Value) {
+			safe = append(safe, attr)
+		}
+	}
+	return safe
+}
+
+// isSafeAttr reports whether an attribute cannot run script
+func isSafeAttr(name, value string) bool {
+	if !attrNamePattern.MatchString(name) {
+		return false
+	}
+	local := strings.ToLower(localName(name))
+	switch {
+	case strings.HasPrefix(local, "on"):
+		return false
+	case local == "style":
+		return !unsafeCSS(value)
+	case local == "href" || local == "src" || local == "action" || local == "formaction":
+		return IsSafeURL(value)
+	}
+	return true
+}
+
+// IsSafeURL reports whether a link target is safe to follow
+// Relative URLs and the http, https, mailto and tel schemes are allowed
+func IsSafeURL(url string) bool {
+	// Browsers ignore control characters and spaces inside the scheme
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, url)
+
+	scheme, _, found := strings.Cut(cleaned, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return true // Relative URL
+	}
+	switch strings.ToLower(scheme) {
+	case "http", "https", "mailto", "tel":
+		return true
+	}
+	return false
+}
+
+// unsafeCSS reports whether CSS text can run script or load other resources
+// that can, in any browser
+func unsafeCSS(css string) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f || r == '\\' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(css))
+	for _, pattern := range []string{"javascript:", "vbscript:", "expression(", "@import", "behavior:", "-moz-binding"} {
+		if strings.Contains(cleaned, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkElement represents an SVG <a> element
+type LinkElement struct {
+	Href     string // Dropped if it is not a safe URL
+	Target   string // e.g. "_blank" (optional)
+	Attrs    []Attr
+	Elements []Element
+}
+
+// Link wraps elements in a hyperlink
+func Link(href string, elements ...Element) *LinkElement {
+	return &LinkElement{Href: href, Elements: elements}
+}
+
+// Tag returns the element name
+func (e *LinkElement) Tag() string { return "a" }
+
+// Attributes returns the link attributes
+func (e *LinkElement) Attributes() []Attr {
+	var attrs []Attr
+	if IsSafeURL(e.Href) {
+		attrs = appendNonEmpty(attrs, "href", e.Href)
+	}
+	attrs = appendNonEmpty(attrs, "target", e.Target)
+	if e.Target == "_blank" {
+		attrs = append(attrs, Attr{"rel", "noopener noreferrer"})
+	}
+	return append(attrs, SafeAttrs(e.Attrs...)...)
+}
+
+// Children returns the linked elements
+func (e *LinkElement) Children() []Element { return e.Elements }
+
+// WriteTo writes the link markup to w
+func (e *LinkElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// Tooltip groups elements with a <title> that browsers show on hover
+func Tooltip(text string, elements ...Element) *GroupElement {
+	return &GroupElement{Elements: append([]Element{&TitleElement{Content: text}}, elements...)}
+}
+
+// NodeAttributes makes a rendered layout node addressable and interactive
+type NodeAttributes struct {
+	ID      string            // id of the node's group
+	Data    map[string]string // data-* attributes, keyed without the prefix
+	Attrs   []Attr            // Further attributes; unsafe ones are dropped
+	Href    string            // Wraps the node in a link (optional)
+	Target  string            // Link target, e.g. "_blank"
+	Tooltip string            // Shown on hover, as a <title>
+}
+
+// attrs returns the attributes for the node's group
+func (n NodeAttributes) attrs() []Attr {
+	var attrs []Attr
+	attrs = appendNonEmpty(attrs, "id", n.ID)
+	for _, name := range sortedKeys(n.Data) {
+		attrs = append(attrs, Data(name, n.Data[name]))
+	}
+	return SafeAttrs(append(attrs, n.Attrs...)...)
+}
+
+// nodeAttributes returns the attributes of a node from NodeAttrsFunc, and
+// adds its path in the tree as data-node when NodeIDs is set
+func (r *Renderer) nodeAttributes(node *layout.Node, ctx *RenderContext) NodeAttributes {
+	var attrs NodeAttributes
+	if r.options.NodeAttrsFunc != nil {
+		attrs = r.options.NodeAttrsFunc(node, ctx.Depth)
+	}
+	if r.options.NodeIDs {
+		attrs.Attrs = append([]Attr{Data("node", ctx.nodePath())}, attrs.Attrs...)
+	}
+	return attrs
+}
@@ -0,0 +1,110 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestSafeAttrs(t *testing.T) {
+	attrs := SafeAttrs(
+		ID("bar-1"),
+		Data("Series", "revenue"),
+		Attr{"onclick", "alert(1)"},
+		Attr{"OnMouseOver", "alert(1)"},
+		Attr{"style", "background:url(javascript:alert(1))"},
+		Attr{"href", "javascript:alert(1)"},
+		Attr{"xlink:href", " JaVa\tScRiPt:alert(1)"},
+		Attr{"bad name", "x"},
+		Attr{"tabindex", "0"},
+		Attr{"href", "/reports?id=1"},
+	)
+
+	expected := ` id="bar-1" data-series="revenue" tabindex="0" href="/reports?id=1"`
+	if got := formatAttrs(attrs); got != expected {
+		t.Errorf("SafeAttrs = %s, want %s", got, expected)
+	}
+}
+
+func TestIsSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		safe bool
+	}{
+		{"https://example.com", true},
+		{"mailto:team@example.com", true},
+		{"#section", true},
+		{"reports/1?a=b:c", true},
+		{"javascript:alert(1)", false},
+		{"java\nscript:alert(1)", false},
+		{"data:text/html,<script>", false},
+		{"vbscript:msgbox", false},
+	}
+	for _, tt := range tests {
+		if got := IsSafeURL(tt.url); got != tt.safe {
+			t.Errorf("IsSafeURL(%q) = %v, want %v", tt.url, got, tt.safe)
+		}
+	}
+}
+
+func TestLink(t *testing.T) {
+	link := Link("https://example.com/?a=1&b=2", &RectElement{Width: 10, Height: 10})
+	link.Target = "_blank"
+	expected := `<a href="https://example.com/?a=1&amp;b=2" target="_blank" rel="noopener noreferrer"><rect`
+	if got := Serialize(link); !strings.HasPrefix(got, expected) {
+		t.Errorf("Link = %s", got)
+	}
+
+	if got := Serialize(Link("javascript:alert(1)", CharData("x"))); got != "<a>x</a>" {
+		t.Errorf("unsafe href should be dropped, got %s", got)
+	}
+
+	tooltip := Serialize(Tooltip("Revenue: 10", &CircleElement{R: 2}))
+	if !strings.HasPrefix(tooltip, "<g><title>Revenue: 10</title><circle") {
+		t.Errorf("Tooltip = %s", tooltip)
+	}
+}
+
+func TestRenderNodeAttributes(t *testing.T) {
+	leaf := &layout.Node{Rect: layout.Rect{Width: 10, Height: 10}}
+	root := &layout.Node{
+		Rect:     layout.Rect{Width: 100, Height: 50},
+		Children: []*layout.Node{{Rect: layout.Rect{Width: 5, Height: 5}}, leaf},
+	}
+
+	opts := DefaultOptions()
+	opts.NodeIDs = true
+	opts.NodeAttrsFunc = func(node *layout.Node, depth int) NodeAttributes {
+		if node != leaf {
+			return NodeAttributes{}
+		}
+		return NodeAttributes{
+			ID:      "leaf",
+			Data:    map[string]string{"value": "42"},
+			Attrs:   []Attr{{"onclick", "steal()"}},
+			Href:    "/details/42",
+			Tooltip: "Value: 42",
+		}
+	}
+	opts.StyleFunc = func(node interface{}, depth int) Style {
+		return Style{Fill: "#ccc", Cursor: "pointer", PointerEvents: "all"}
+	}
+
+	svg := RenderToSVG(root, opts)
+	for _, expected := range []string{
+		`<g data-node="0">`,
+		`<g data-node="0.0">`,
+		`<a href="/details/42"><g id="leaf" data-value="42" data-node="0.1">`,
+		"<title>Value: 42</title>",
+		`cursor="pointer" pointer-events="all"`,
+		"</g></a>",
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, svg)
+		}
+	}
+	if strings.Contains(svg, "onclick") {
+		t.Errorf("Event handlers should be dropped:\n%s", svg)
+	}
+}
@@ -0,0 +1,88 @@
+package svg
+
+import (
+	"github.com/SCKelemen/layout"
+)
+
+// Box is an axis-aligned rectangle in user units
+type Box struct {
+	X, Y, Width, Height float64
+}
+
+// Edges holds a value for each side of a box
+type Edges struct {
+	Top, Right, Bottom, Left float64
+}
+
+// Inset returns the box shrunk by the edges; sizes never go below zero
+func (b Box) Inset(e Edges) Box {
+	out := Box{
+		X:      b.X + e.Left,
+		Y:      b.Y + e.Top,
+		Width:  b.Width - e.Left - e.Right,
+		Height: b.Height - e.Top - e.Bottom,
+	}
+	if out.Width < 0 {
+		out.Width = 0
+	}
+	if out.Height < 0 {
+		out.Height = 0
+	}
+	return out
+}
+
+// Outset returns the box grown by the edges
+func (b Box) Outset(e Edges) Box {
+	return Box{
+		X:      b.X - e.Left,
+		Y:      b.Y - e.Top,
+		Width:  b.Width + e.Left + e.Right,
+		Height: b.Height + e.Top + e.Bottom,
+	}
+}
+
+// BoxModel holds the CSS box areas of a layout node
+// Border is the node's Rect; the other areas are derived from its style
+type BoxModel struct {
+	Margin  Box
+	Border  Box
+	Padding Box
+	Content Box
+
+	MarginWidths  Edges
+	BorderWidths  Edges
+	PaddingWidths Edges
+}
+
+// BoxModel computes the box areas of a layout node
+// Relative lengths are resolved against the output size and DefaultFontSize
+func (r *Renderer) BoxModel(node *layout.Node) BoxModel {
+	fontSize := DefaultFontSize
+	if ts := node.Style.TextStyle; ts != nil && ts.FontSize > 0 {
+		fontSize = ts.FontSize
+	}
+	ctx := &layout.LayoutContext{
+		ViewportWidth:  r.options.Width,
+		ViewportHeight: r.options.Height,
+		RootFontSize:   DefaultFontSize,
+	}
+	edges := func(s layout.Spacing) Edges {
+		return Edges{
+			Top:    layout.ResolveLength(s.Top, ctx, fontSize),
+			Right:  layout.ResolveLength(s.Right, ctx, fontSize),
+			Bottom: layout.ResolveLength(s.Bottom, ctx, fontSize),
+			Left:   layout.ResolveLength(s.Left, ctx, fontSize),
+		}
+	}
+
+	m := BoxModel{
+		Border:        Box{X: node.Rect.X, Y: node.Rect.Y, Width: node.Rect.Width, Height: node.Rect.Height},
+		MarginWidths:  edges(node.Style.Margin),
+		BorderWidths:  edges(node.Style.Border),
+		PaddingWidths: edges(node.Style.Padding),
+	}
+	m.Margin = m.Border.Outset(m.MarginWidths)
+	m.Padding = m.Border.Inset(m.BorderWidths)
+	m.Content = m.Padding.Inset(m.PaddingWidths)
+	return m
+}
@@ -2,17 +2,14 @@ package svg
 
 import (
 	"fmt"
+	"io"
 	"strings"
-	"sync/atomic"
 )
 
-// Global counter for unique clipPath IDs across all renderers
-// This ensures thread-safe unique ID generation
-var clipPathCounter int64
-
 // ClipPathManager manages SVG clipPath definitions and generates unique IDs
 type ClipPathManager struct {
 	paths []ClipPath
+	ids   *IDAllocator
 }
 
 // ClipPath represents an SVG clipPath definition
@@ -21,24 +18,51 @@ type ClipPath struct {
 	Path string // SVG path or shapes to use for clipping
 }
 
+// ClipPathElement represents an SVG <clipPath> element
+type ClipPathElement struct {
+	ID       string
+	Elements []Element
+}
+
+// Tag returns the element name
+func (e *ClipPathElement) Tag() string { return "clipPath" }
+
+// Attributes returns the clipPath attributes
+func (e *ClipPathElement) Attributes() []Attr { return []Attr{{"id", e.ID}} }
+
+// Children returns the clipping shapes
+func (e *ClipPathElement) Children() []Element { return e.Elements }
+
+// WriteTo writes the clipPath markup to w
+func (e *ClipPathElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
 // NewClipPathManager creates a new clipPath manager
+// IDs are numbered from "clip-1" by the manager's own allocator
 func NewClipPathManager() *ClipPathManager {
+	return NewClipPathManagerWithIDs(NewIDAllocator(""))
+}
+
+// NewClipPathManagerWithIDs creates a clipPath manager that takes its IDs
+// from ids, so they can be shared with other definitions of a document
+func NewClipPathManagerWithIDs(ids *IDAllocator) *ClipPathManager {
 	return &ClipPathManager{
 		paths: make([]ClipPath, 0),
+		ids:   ids,
 	}
 }
 
 // GenerateID generates a unique clipPath ID
 func (m *ClipPathManager) GenerateID() string {
-	id := atomic.AddInt64(&clipPathCounter, 1)
-	return fmt.Sprintf("clip-%d", id)
+	if m.ids == nil {
+		m.ids = NewIDAllocator("")
+	}
+	return m.ids.Next("clip")
 }
 
 // AddRoundedRect adds a rounded rectangle clipPath and returns its ID
 func (m *ClipPathManager) AddRoundedRect(x, y, width, height, radius float64) string {
 	id := m.GenerateID()
-	path := fmt.Sprintf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" rx="%.2f" ry="%.2f"/>`,
-		x, y, width, height, radius, radius)
+	path := Serialize(&RectElement{X: x, Y: y, Width: width, Height: height, RX: radius, RY: radius})
 
 	m.paths = append(m.paths, ClipPath{
 		ID:   id,
@@ -51,8 +75,7 @@ func (m *ClipPathManager) AddRoundedRect(x, y, width, height, radius float64) st
 // AddRect adds a rectangle clipPath and returns its ID
 func (m *ClipPathManager) AddRect(x, y, width, height float64) string {
 	id := m.GenerateID()
-	path := fmt.Sprintf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f"/>`,
-		x, y, width, height)
+	path := Serialize(&RectElement{X: x, Y: y, Width: width, Height: height})
 
 	m.paths = append(m.paths, ClipPath{
 		ID:   id,
@@ -65,8 +88,7 @@ func (m *ClipPathManager) AddRect(x, y, width, height float64) string {
 // AddCircle adds a circle clipPath and returns its ID
 func (m *ClipPathManager) AddCircle(cx, cy, r float64) string {
 	id := m.GenerateID()
-	path := fmt.Sprintf(`<circle cx="%.2f" cy="%.2f" r="%.2f"/>`,
-		cx, cy, r)
+	path := Serialize(&CircleElement{CX: cx, CY: cy, R: r})
 
 	m.paths = append(m.paths, ClipPath{
 		ID:   id,
@@ -86,6 +108,15 @@ func (m *ClipPathManager) AddCustom(pathContent string) string {
 	return id
 }
 
+// Elements returns all clipPaths as elements for use in <defs>
+func (m *ClipPathManager) Elements() []Element {
+	elements := make([]Element, len(m.paths))
+	for i, cp := range m.paths {
+		elements[i] = &ClipPathElement{ID: cp.ID, Elements: []Element{Raw(cp.Path)}}
+	}
+	return elements
+}
+
 // ToSVGDefs converts all clipPaths to SVG <defs> content
 func (m *ClipPathManager) ToSVGDefs() string {
 	if len(m.paths) == 0 {
@@ -95,7 +126,7 @@ func (m *ClipPathManager) ToSVGDefs() string {
 	var b strings.Builder
 
 	for _, cp := range m.paths {
-		b.WriteString(fmt.Sprintf(`<clipPath id="%s">%s</clipPath>`, cp.ID, cp.Path))
+		(&ClipPathElement{ID: cp.ID, Elements: []Element{Raw(cp.Path)}}).WriteTo(&b)
 		b.WriteString("\n    ")
 	}
 
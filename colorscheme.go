@@ -0,0 +1,168 @@
+package svg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ColorScheme selects the light or dark variant of adaptive colors
+type ColorScheme string
+
+const (
+	ColorSchemeLight ColorScheme = "light"
+	ColorSchemeDark  ColorScheme = "dark"
+)
+
+// Var returns a reference to a CSS custom property, e.g. Var("fg") is "var(--fg)"
+// A fallback value may be given for viewers that do not define the property
+func Var(name string, fallback ...string) string {
+	name = "--" + strings.TrimPrefix(name, "--")
+	if len(fallback) > 0 && fallback[0] != "" {
+		return fmt.Sprintf("var(%s, %s)", name, fallback[0])
+	}
+	return fmt.Sprintf("var(%s)", name)
+}
+
+// AddColorScheme adds paired color tokens as CSS custom properties
+//
+// The light tokens are defined on the svg root, so they do not leak into an
+// HTML page the SVG is inlined in, and the dark tokens override them inside
+// @media (prefers-color-scheme: dark). Token names are given without
+// the leading "--", so a "fg" token is referenced from a Style as Var("fg").
+func (ss *StyleSheet) AddColorScheme(light, dark map[string]string) *StyleSheet {
+	ss.Add(Rule(themeSelector, customProperties(light)...))
+	if len(dark) > 0 {
+		ss.AddMedia("(prefers-color-scheme: dark)", Rule(themeSelector, customProperties(dark)...))
+	}
+	return ss
+}
+
+// customProperties returns declarations for tokens, sorted by name
+func customProperties(tokens map[string]string) []Declaration {
+	decls := make([]Declaration, 0, len(tokens))
+	for _, name := range sortedKeys(tokens) {
+		decls = append(decls, Decl("--"+strings.TrimPrefix(name, "--"), tokens[name]))
+	}
+	return decls
+}
+
+// CustomProperties returns the custom properties defined on :root (or svg)
+// for the given color scheme, including overrides from matching
+// @media (prefers-color-scheme: ...) blocks. Names keep their leading "--".
+func (ss *StyleSheet) CustomProperties(scheme ColorScheme) map[string]string {
+	props := make(map[string]string)
+	collectCustomProperties(ss.Rules, scheme, props)
+	return props
+}
+
+func collectCustomProperties(rules []StyleRule, scheme ColorScheme, props map[string]string) {
+	for _, rule := range rules {
+		if rule.IsAtRule() {
+			if atRuleName(rule.Selector) == "media" && matchesColorScheme(rule.Selector, scheme) {
+				collectCustomProperties(rule.Rules, scheme, props)
+			}
+			continue
+		}
+		if !isRootSelector(rule.Selector) {
+			continue
+		}
+		for _, decl := range rule.OrderedDeclarations() {
+			if strings.HasPrefix(decl.Property, "--") {
+				props[decl.Property] = decl.Value
+			}
+		}
+	}
+}
+
+// isRootSelector reports whether a selector list targets the document root
+func isRootSelector(selector string) bool {
+	for _, s := range strings.Split(selector, ",") {
+		switch strings.TrimSpace(s) {
+		case ":root", "svg", "*":
+			return true
+		}
+	}
+	return false
+}
+
+var colorSchemeQueryPattern = regexp.MustCompile(`prefers-color-scheme\s*:\s*(light|dark)`)
+
+// matchesColorScheme reports whether a media query applies to the scheme
+// Queries that do not test prefers-color-scheme never match, since the
+// rasterizer does not evaluate other media features
+func matchesColorScheme(query string, scheme ColorScheme) bool {
+	m := colorSchemeQueryPattern.FindStringSubmatch(query)
+	if m == nil {
+		return false
+	}
+	if scheme == "" {
+		scheme = ColorSchemeLight
+	}
+	return m[1] == string(scheme)
+}
+
+var varPattern = regexp.MustCompile(`var\(\s*(--[\w-]+)\s*(?:,\s*([^()]*))?\)`)
+
+// resolveVars replaces var() references with the values of custom properties
+// Unknown properties use their fallback, or are left unresolved if there is none
+func resolveVars(value string, props map[string]string) string {
+	// Custom properties may reference each other; bound the depth to stop cycles
+	for i := 0; i < 8 && strings.Contains(value, "var("); i++ {
+		resolved := varPattern.ReplaceAllStringFunc(value, func(ref string) string {
+			m := varPattern.FindStringSubmatch(ref)
+			if v, ok := props[m[1]]; ok {
+				return v
+			}
+			if m[2] != "" {
+				return strings.TrimSpace(m[2])
+			}
+			return ref
+		})
+		if resolved == value {
+			break
+		}
+		value = resolved
+	}
+	return value
+}
+
+// AdaptiveStyleSheet generates a stylesheet whose colors follow the
+// viewer's color scheme
+//
+// Palette colors of light are defined on the svg root and those of dark are
+// defined under @media (prefers-color-scheme: dark). The role classes
+// reference the colors through var(), so they flip with the scheme.
+func AdaptiveStyleSheet(light, dark *Theme) *StyleSheet {
+	root := Rule(themeSelector, light.colorDeclarations()...)
+	root.Declarations = append(root.Declarations, light.tokenDeclarations()...)
+
+	ss := &StyleSheet{}
+	ss.Add(root)
+	ss.AddMedia("(prefers-color-scheme: dark)", Rule(themeSelector, dark.colorDeclarations()...))
+	ss.Add(light.colorVars().classRules()...)
+	return ss
+}
+
+// colorVars returns a copy of the theme whose palette colors are var()
+// references to the theme's color tokens
+func (t *Theme) colorVars() *Theme {
+	vars := *t
+	vars.Palette = Palette{
+		Background: Var(themeProperty("color-background")),
+		Foreground: Var(themeProperty("color-foreground")),
+		Muted:      Var(themeProperty("color-muted")),
+		Accent:     Var(themeProperty("color-accent")),
+		Border:     Var(themeProperty("color-border")),
+	}
+	for i := range t.Palette.Surfaces {
+		vars.Palette.Surfaces = append(vars.Palette.Surfaces, Var(themeProperty(fmt.Sprintf("color-surface-%d", i))))
+	}
+	if len(t.Palette.Colors) > 0 {
+		vars.Palette.Colors = make(map[string]string, len(t.Palette.Colors))
+		for name := range t.Palette.Colors {
+			vars.Palette.Colors[name] = Var(themeProperty("color-" + name))
+		}
+	}
+	return &vars
+}
@@ -0,0 +1,135 @@
+package svg
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestVar(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{"plain", Var("fg"), "var(--fg)"},
+		{"dashes kept", Var("--fg"), "var(--fg)"},
+		{"fallback", Var("fg", "#000"), "var(--fg, #000)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("got %q, want %q", tt.got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddColorScheme(t *testing.T) {
+	ss := (&StyleSheet{}).AddColorScheme(
+		map[string]string{"fg": "#111", "bg": "#fff"},
+		map[string]string{"fg": "#eee", "bg": "#000"},
+	)
+
+	expected := "svg {\n    --bg: #fff;\n    --fg: #111;\n}\n" +
+		"@media (prefers-color-scheme: dark) {\n    svg {\n        --bg: #000;\n        --fg: #eee;\n    }\n}"
+	if got := ss.CSS(); got != expected {
+		t.Errorf("got:\n%s\nwant:\n%s", got, expected)
+	}
+
+	light := ss.CustomProperties(ColorSchemeLight)
+	dark := ss.CustomProperties(ColorSchemeDark)
+	if light["--fg"] != "#111" || dark["--fg"] != "#eee" {
+		t.Errorf("unexpected properties: light=%v dark=%v", light, dark)
+	}
+}
+
+func TestResolveVars(t *testing.T) {
+	props := map[string]string{
+		"--fg":     "#111",
+		"--accent": "var(--fg)",
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var(--fg)", "#111"},
+		{"var( --fg )", "#111"},
+		{"var(--accent)", "#111"},
+		{"var(--missing, red)", "red"},
+		{"var(--missing)", "var(--missing)"},
+		{"#fff", "#fff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := resolveVars(tt.input, props); got != tt.expected {
+				t.Errorf("resolveVars(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderAdaptiveTheme(t *testing.T) {
+	root := &layout.Node{Rect: layout.Rect{Width: 100, Height: 100}}
+
+	opts := DefaultOptions()
+	opts.DarkTheme = DarkTheme()
+	output := RenderToSVG(root, opts)
+
+	tests := []string{
+		"@media (prefers-color-scheme: dark)",
+		"--theme-color-surface-0: #f6f8fa;",
+		"--theme-color-surface-0: #161b22;",
+		`fill="var(--theme-color-surface-0)"`,
+		`fill="var(--theme-color-background)"`,
+		"fill: var(--theme-color-foreground);",
+	}
+
+	for _, want := range tests {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestExportColorScheme(t *testing.T) {
+	ss := (&StyleSheet{}).AddColorScheme(
+		map[string]string{"fg": "#ff0000"},
+		map[string]string{"fg": "#0000ff"},
+	)
+	svgData := `<svg width="10" height="10">` + ss.ToSVG() +
+		`<rect x="0" y="0" width="10" height="10" fill="var(--fg)"/></svg>`
+
+	tests := []struct {
+		name    string
+		scheme  ColorScheme
+		r, g, b uint32
+	}{
+		{"default", "", 0xffff, 0, 0},
+		{"light", ColorSchemeLight, 0xffff, 0, 0},
+		{"dark", ColorSchemeDark, 0, 0, 0xffff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Export(svgData, ExportOptions{Format: FormatPNG, ColorScheme: tt.scheme})
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("png.Decode: %v", err)
+			}
+			r, g, b, _ := img.At(5, 5).RGBA()
+			if r != tt.r || g != tt.g || b != tt.b {
+				t.Errorf("pixel = (%x, %x, %x), want (%x, %x, %x)", r, g, b, tt.r, tt.g, tt.b)
+			}
+		})
+	}
+}
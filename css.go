@@ -0,0 +1,244 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// nestedAtRules are at-rules whose blocks contain rules rather than declarations
+var nestedAtRules = map[string]bool{
+	"media":             true,
+	"supports":          true,
+	"document":          true,
+	"-moz-document":     true,
+	"layer":             true,
+	"container":         true,
+	"scope":             true,
+	"starting-style":    true,
+	"keyframes":         true,
+	"-webkit-keyframes": true,
+	"-moz-keyframes":    true,
+}
+
+// ParseStyleSheet parses CSS text into a StyleSheet
+//
+// Qualified rules, block at-rules (@media, @supports, @keyframes, @font-face, ...)
+// and statement at-rules (@import, @charset, ...) are supported. Comments are
+// dropped, and declarations keep their source order.
+func ParseStyleSheet(css string) (*StyleSheet, error) {
+	p := &cssParser{s: css}
+	rules, err := p.rules(false)
+	if err != nil {
+		return nil, err
+	}
+	return &StyleSheet{Rules: rules}, nil
+}
+
+// cssParser is a small recursive-descent CSS parser
+type cssParser struct {
+	s   string
+	pos int
+}
+
+// rules parses rules until the end of input, or a closing brace when nested
+func (p *cssParser) rules(nested bool) ([]StyleRule, error) {
+	var rules []StyleRule
+
+	for {
+		p.skipSpaceAndComments()
+		if p.done() {
+			if nested {
+				return nil, fmt.Errorf("unexpected end of CSS: missing '}'")
+			}
+			return rules, nil
+		}
+		if p.s[p.pos] == '}' {
+			if !nested {
+				return nil, fmt.Errorf("unexpected '}' at offset %d", p.pos)
+			}
+			p.pos++
+			return rules, nil
+		}
+
+		prelude, terminator := p.until("{;}")
+		prelude = collapseWhitespace(prelude)
+
+		switch terminator {
+		case ';':
+			if !strings.HasPrefix(prelude, "@") {
+				return nil, fmt.Errorf("unexpected ';' after %q", prelude)
+			}
+			rules = append(rules, StyleRule{Selector: prelude, Statement: true})
+			continue
+		case '{':
+		default:
+			if strings.HasPrefix(prelude, "@") && !nested && p.done() {
+				rules = append(rules, StyleRule{Selector: prelude, Statement: true})
+				continue
+			}
+			return nil, fmt.Errorf("expected '{' after %q", prelude)
+		}
+
+		rule := StyleRule{Selector: prelude}
+		if nestedAtRules[atRuleName(prelude)] {
+			children, err := p.rules(true)
+			if err != nil {
+				return nil, err
+			}
+			rule.Rules = children
+		} else {
+			decls, err := p.declarations()
+			if err != nil {
+				return nil, err
+			}
+			rule.Declarations = decls
+		}
+		rules = append(rules, rule)
+	}
+}
+
+// declarations parses declarations up to and including the closing brace
+func (p *cssParser) declarations() ([]Declaration, error) {
+	var decls []Declaration
+
+	for {
+		p.skipSpaceAndComments()
+		if p.done() {
+			return nil, fmt.Errorf("unexpected end of CSS: missing '}'")
+		}
+		if p.s[p.pos] == '}' {
+			p.pos++
+			return decls, nil
+		}
+
+		text, terminator := p.until(";}")
+		if terminator == '}' {
+			p.pos-- // let the loop consume the brace
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		colon := strings.IndexByte(text, ':')
+		if colon < 0 {
+			return nil, fmt.Errorf("invalid declaration %q", text)
+		}
+		decls = append(decls, Declaration{
+			Property: strings.TrimSpace(text[:colon]),
+			Value:    strings.TrimSpace(text[colon+1:]),
+		})
+	}
+}
+
+// until reads up to one of the stop characters outside strings, parentheses
+// and comments, consuming the stop character; it returns 0 at end of input
+func (p *cssParser) until(stops string) (string, byte) {
+	var b strings.Builder
+	depth := 0
+
+	for !p.done() {
+		c := p.s[p.pos]
+
+		switch {
+		case c == '/' && strings.HasPrefix(p.s[p.pos:], "/*"):
+			p.skipComment()
+			continue
+		case c == '"' || c == '\'':
+			b.WriteString(p.quoted())
+			continue
+		case c == '\\' && p.pos+1 < len(p.s):
+			b.WriteString(p.s[p.pos : p.pos+2])
+			p.pos += 2
+			continue
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case depth == 0 && strings.IndexByte(stops, c) >= 0:
+			p.pos++
+			return b.String(), c
+		}
+
+		b.WriteByte(c)
+		p.pos++
+	}
+
+	return b.String(), 0
+}
+
+// quoted reads a quoted string including its quotes
+func (p *cssParser) quoted() string {
+	quote := p.s[p.pos]
+	start := p.pos
+	p.pos++
+	for !p.done() {
+		c := p.s[p.pos]
+		p.pos++
+		if c == '\\' && !p.done() {
+			p.pos++
+			continue
+		}
+		if c == quote {
+			break
+		}
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *cssParser) skipSpaceAndComments() {
+	for !p.done() {
+		switch {
+		case strings.HasPrefix(p.s[p.pos:], "/*"):
+			p.skipComment()
+		case strings.IndexByte(" \t\r\n\f", p.s[p.pos]) >= 0:
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *cssParser) skipComment() {
+	end := strings.Index(p.s[p.pos+2:], "*/")
+	if end < 0 {
+		p.pos = len(p.s)
+		return
+	}
+	p.pos += end + 4
+}
+
+func (p *cssParser) done() bool { return p.pos >= len(p.s) }
+
+// atRuleName returns the lower-case name of an at-rule prelude, or "" for other rules
+func atRuleName(prelude string) string {
+	if !strings.HasPrefix(prelude, "@") {
+		return ""
+	}
+	name := prelude[1:]
+	if i := strings.IndexAny(name, " \t\r\n({"); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(name)
+}
+
+// quoteCSS returns s as a CSS string in double quotes
+// Quotes and backslashes are escaped, and control characters written as
+// hex escapes, as CSS strings cannot contain them literally
+func quoteCSS(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, "\\%x ", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
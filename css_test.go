@@ -0,0 +1,148 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStyleSheetDeterministic(t *testing.T) {
+	ss := &StyleSheet{}
+	ss.AddRule(".a", map[string]string{"stroke": "#000", "fill": "#fff", "opacity": "0.5"})
+
+	first := ss.ToSVG()
+	for i := 0; i < 20; i++ {
+		if ss.ToSVG() != first {
+			t.Fatal("Expected identical output on every call")
+		}
+	}
+
+	expected := "<style>\n    .a {\n        fill: #fff;\n        opacity: 0.5;\n        stroke: #000;\n    }\n</style>"
+	if first != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, first)
+	}
+}
+
+func TestStyleSheetAtRules(t *testing.T) {
+	ss := &StyleSheet{}
+	ss.Add(StyleRule{Selector: "@import url(theme.css)", Statement: true})
+	ss.AddFontFace(Decl("font-family", "Inter"), Decl("src", `url("inter.woff2")`))
+	ss.AddMedia("(max-width: 600px)", Rule(".label", Decl("font-size", "10px")))
+	ss.AddKeyframes("spin", Rule("from", Decl("transform", "rotate(0deg)")), Rule("to", Decl("transform", "rotate(360deg)")))
+
+	expected := `@import url(theme.css);
+@font-face {
+    font-family: Inter;
+    src: url("inter.woff2");
+}
+@media (max-width: 600px) {
+    .label {
+        font-size: 10px;
+    }
+}
+@keyframes spin {
+    from {
+        transform: rotate(0deg);
+    }
+    to {
+        transform: rotate(360deg);
+    }
+}`
+	if css := ss.CSS(); css != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, css)
+	}
+}
+
+func TestParseStyleSheet(t *testing.T) {
+	css := `
+		@charset "utf-8";
+		/* labels */
+		.label, text.title { font: 12px "Helvetica Neue", sans-serif; fill: #333 }
+		@font-face { font-family: "X"; src: url(data:font/woff2;base64,AAAA) format("woff2"); }
+		@media (prefers-color-scheme: dark) {
+			.label { fill: #eee; }
+		}
+		@keyframes pulse { 0% { opacity: 1 } 50% { opacity: .5 } }
+	`
+
+	ss, err := ParseStyleSheet(css)
+	if err != nil {
+		t.Fatalf("ParseStyleSheet failed: %v", err)
+	}
+	if len(ss.Rules) != 5 {
+		t.Fatalf("Expected 5 rules, got %d: %+v", len(ss.Rules), ss.Rules)
+	}
+
+	if ss.Rules[0].Selector != `@charset "utf-8"` {
+		t.Errorf("Expected @charset statement, got %q", ss.Rules[0].Selector)
+	}
+
+	label := ss.Rules[1]
+	if label.Selector != ".label, text.title" {
+		t.Errorf("Unexpected selector %q", label.Selector)
+	}
+	if len(label.Declarations) != 2 || label.Declarations[0].Property != "font" || label.Declarations[1].Value != "#333" {
+		t.Errorf("Unexpected declarations %+v", label.Declarations)
+	}
+
+	if src, _ := ss.Rules[2].Get("src"); src != `url(data:font/woff2;base64,AAAA) format("woff2")` {
+		t.Errorf("Expected semicolons inside url() to be preserved, got %q", src)
+	}
+
+	media := ss.Rules[3]
+	if len(media.Rules) != 1 || media.Rules[0].Selector != ".label" {
+		t.Errorf("Expected nested rule in @media, got %+v", media.Rules)
+	}
+
+	keyframes := ss.Rules[4]
+	if len(keyframes.Rules) != 2 || keyframes.Rules[1].Selector != "50%" {
+		t.Errorf("Expected keyframe blocks, got %+v", keyframes.Rules)
+	}
+
+	// Round trip through the writer
+	reparsed, err := ParseStyleSheet(ss.CSS())
+	if err != nil {
+		t.Fatalf("Reparse failed: %v", err)
+	}
+	if reparsed.CSS() != ss.CSS() {
+		t.Errorf("Round trip mismatch:\n%s\n---\n%s", ss.CSS(), reparsed.CSS())
+	}
+}
+
+func TestParseStyleSheetEmptyAtRules(t *testing.T) {
+	tests := []struct {
+		css      string
+		expected string
+	}{
+		{`@import url(a.css);`, `@import url(a.css);`},
+		{`@font-face {}`, "@font-face {\n}"},
+		{`@media print { }`, "@media print {\n}"},
+	}
+
+	for _, tt := range tests {
+		ss, err := ParseStyleSheet(tt.css)
+		if err != nil {
+			t.Fatalf("ParseStyleSheet(%q) failed: %v", tt.css, err)
+		}
+		if css := ss.CSS(); css != tt.expected {
+			t.Errorf("ParseStyleSheet(%q).CSS() = %q, expected %q", tt.css, css, tt.expected)
+		}
+	}
+}
+
+func TestParseStyleSheetErrors(t *testing.T) {
+	for _, css := range []string{".a { fill: red", ".a fill: red; }", "}", ".a { fill }"} {
+		if _, err := ParseStyleSheet(css); err == nil {
+			t.Errorf("Expected error for %q", css)
+		}
+	}
+}
+
+func TestDefaultStyleSheetOrder(t *testing.T) {
+	out := DefaultStyleSheet().ToSVG()
+	family := strings.Index(out, "font-family: ui-monospace")
+	size := strings.Index(out, "font-size: 12px")
+	spacing := strings.Index(out, "letter-spacing")
+	if !(family < size && size < spacing) {
+		t.Errorf("Expected .mono declarations in source order, got:\n%s", out)
+	}
+}
@@ -0,0 +1,167 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// culling decides which nodes are drawn when Options.Cull or
+// Options.MinNodeSize is set
+type culling struct {
+	viewport Box
+	cull     bool
+	minSize  float64                        // Minimum size in user units
+	bounds   map[*layout.Node]subtreeBounds // Computed bounds, by node
+}
+
+// subtreeBounds holds the extent of a node and its descendants
+// Bounds are unknown when the subtree has a transform
+type subtreeBounds struct {
+	box   Box
+	known bool
+}
+
+// newCulling returns the culling for the options, or nil if it is disabled
+func newCulling(opts Options) *culling {
+	if !opts.Cull && opts.MinNodeSize <= 0 {
+		return nil
+	}
+	viewport, ok := parseViewBox(opts.ViewBox)
+	if !ok {
+		viewport = Box{Width: opts.Width, Height: opts.Height}
+	}
+
+	c := &culling{viewport: viewport, cull: opts.Cull, bounds: make(map[*layout.Node]subtreeBounds)}
+	if opts.MinNodeSize > 0 && opts.Width > 0 {
+		// Convert output pixels to user units
+		c.minSize = opts.MinNodeSize * viewport.Width / opts.Width
+	}
+	return c
+}
+
+// pageCulling returns culling against the part of a tree shown on a page
+func pageCulling(opts Options, viewport Box) *culling {
+	opts.Cull = true
+	c := newCulling(opts)
+	c.viewport = viewport
+	return c
+}
+
+// parseViewBox parses a viewBox attribute such as "0 0 100 50"
+func parseViewBox(viewBox string) (Box, bool) {
+	fields := strings.FieldsFunc(viewBox, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) != 4 {
+		return Box{}, false
+	}
+	var v [4]float64
+	for i, field := range fields {
+		f, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return Box{}, false
+		}
+		v[i] = f
+	}
+	if v[2] <= 0 || v[3] <= 0 {
+		return Box{}, false
+	}
+	return Box{X: v[0], Y: v[1], Width: v[2], Height: v[3]}, true
+}
+
+// visible reports whether a node's subtree may intersect the viewport
+func (c *culling) visible(node *layout.Node) bool {
+	if c == nil || !c.cull {
+		return true
+	}
+	b := c.subtreeBounds(node)
+	if !b.known {
+		return true
+	}
+	v := c.viewport
+	return b.box.X < v.X+v.Width && b.box.X+b.box.Width > v.X &&
+		b.box.Y < v.Y+v.Height && b.box.Y+b.box.Height > v.Y
+}
+
+// tiny reports whether a node's subtree is below the minimum size, and
+// returns its bounds
+func (c *culling) tiny(node *layout.Node) (Box, bool) {
+	if c == nil || c.minSize <= 0 {
+		return Box{}, false
+	}
+	b := c.subtreeBounds(node)
+	return b.box, b.known && b.box.Width < c.minSize && b.box.Height < c.minSize
+}
+
+// subtreeBounds returns the union of the rects of a node and its descendants
+func (c *culling) subtreeBounds(node *layout.Node) subtreeBounds {
+	if b, ok := c.bounds[node]; ok {
+		return b
+	}
+
+	rect := node.Rect
+	b := subtreeBounds{box: Box{X: rect.X, Y: rect.Y, Width: rect.Width, Height: rect.Height}, known: GetTransformFromNode(node) == ""}
+	for _, child := range node.Children {
+		if !b.known {
+			break
+		}
+		cb := c.subtreeBounds(child)
+		b.known = cb.known
+		b.box = unionBox(b.box, cb.box)
+	}
+	c.bounds[node] = b
+	return b
+}
+
+// unionBox returns the smallest box containing a and b
+func unionBox(a, b Box) Box {
+	x, y := math.Min(a.X, b.X), math.Min(a.Y, b.Y)
+	return Box{
+		X:      x,
+		Y:      y,
+		Width:  math.Max(a.X+a.Width, b.X+b.Width) - x,
+		Height: math.Max(a.Y+a.Height, b.Y+b.Height) - y,
+	}
+}
+
+// aggregate collects tiny nodes into cells of the minimum size, so each
+// covered cell is drawn once however many nodes fall in it
+type aggregate struct {
+	size    float64
+	covered map[[2]int]bool
+}
+
+// aggregate returns an empty aggregate, or nil if there is no minimum size
+func (c *culling) aggregate() *aggregate {
+	if c == nil || c.minSize <= 0 {
+		return nil
+	}
+	return &aggregate{size: c.minSize, covered: make(map[[2]int]bool)}
+}
+
+// add adds a tiny node with the given bounds and fill to the cell holding
+// its center. The first node in a cell sets its fill and gets the cell's
+// rect, so cells are drawn in document order; later nodes return nil.
+func (a *aggregate) add(r *Renderer, b Box, fill string) Element {
+	key := [2]int{int(math.Floor((b.X + b.Width/2) / a.size)), int(math.Floor((b.Y + b.Height/2) / a.size))}
+	if fill == "" || a.covered[key] {
+		return nil
+	}
+	a.covered[key] = true
+	return &RectElement{
+		X:      float64(key[0]) * a.size,
+		Y:      float64(key[1]) * a.size,
+		Width:  a.size,
+		Height: a.size,
+		Style:  r.classStyle(Style{Fill: fill}),
+	}
+}
+
+// aggregateFill returns the color a tiny node is drawn with
+func aggregateFill(style Style) string {
+	if style.Fill == "" || style.Fill == "none" {
+		return style.Stroke
+	}
+	return style.Fill
+}
@@ -0,0 +1,169 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestRenderCulling(t *testing.T) {
+	root := &layout.Node{
+		Rect: layout.Rect{Width: 1000, Height: 1000},
+		Children: []*layout.Node{
+			{Rect: layout.Rect{X: 10, Y: 10, Width: 50, Height: 50}},
+			{Rect: layout.Rect{X: 500, Y: 500, Width: 50, Height: 50}},
+			// Outside itself, but a child reaches into the viewport
+			{Rect: layout.Rect{X: 300, Y: 0, Width: 10, Height: 10}, Children: []*layout.Node{
+				{Rect: layout.Rect{X: 90, Y: 20, Width: 5, Height: 5}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		viewBox  string
+		cull     bool
+		expected []string
+		excluded []string
+	}{
+		{
+			name:     "without culling every node is drawn",
+			expected: []string{`x="10.00"`, `x="500.00"`},
+		},
+		{
+			name:     "nodes outside the viewport are skipped",
+			cull:     true,
+			expected: []string{`x="10.00"`, `x="90.00"`},
+			excluded: []string{`x="500.00"`},
+		},
+		{
+			name:     "the viewBox is the viewport",
+			viewBox:  "450 450 200 200",
+			cull:     true,
+			expected: []string{`x="500.00"`},
+			excluded: []string{`x="10.00"`, `x="90.00"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := WithSize(200, 200)
+			opts.ViewBox = tt.viewBox
+			opts.Cull = tt.cull
+
+			svg := RenderToSVG(root, opts)
+			for _, expected := range tt.expected {
+				if !strings.Contains(svg, expected) {
+					t.Errorf("Expected %q in:\n%s", expected, svg)
+				}
+			}
+			for _, excluded := range tt.excluded {
+				if strings.Contains(svg, excluded) {
+					t.Errorf("Did not expect %q in:\n%s", excluded, svg)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderCullingTransformedAncestor(t *testing.T) {
+	root := &layout.Node{
+		Rect: layout.Rect{Width: 100, Height: 100},
+		Children: []*layout.Node{{
+			Rect:  layout.Rect{Width: 100, Height: 100},
+			Style: layout.Style{Transform: layout.Translate(550, 0)},
+			Children: []*layout.Node{
+				// Outside the viewport by its rect, inside once translated
+				{Rect: layout.Rect{X: -500, Width: 10, Height: 10}},
+				// Tiny by its rect, but scaled up by its parent
+				{Rect: layout.Rect{X: -480, Width: 0.5, Height: 0.5}, Style: layout.Style{Transform: layout.Scale(40, 40)}},
+			},
+		}},
+	}
+	opts := WithSize(200, 200)
+	opts.Cull = true
+	opts.MinNodeSize = 1
+
+	svg := RenderToSVG(root, opts)
+	for _, expected := range []string{`<rect x="-500.00"`, `<rect x="-480.00"`} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q under the transformed parent in:\n%s", expected, svg)
+		}
+	}
+}
+
+func TestRenderMinNodeSize(t *testing.T) {
+	root := &layout.Node{Rect: layout.Rect{Width: 100, Height: 100}}
+	for i := 0; i < 100; i++ {
+		// Ten dots per 1x1 cell, in ten cells along the diagonal
+		x := float64(i/10) + float64(i%10)*0.05
+		root.Children = append(root.Children, &layout.Node{Rect: layout.Rect{X: x, Y: x, Width: 0.1, Height: 0.1}})
+	}
+	root.Children = append(root.Children, &layout.Node{Rect: layout.Rect{X: 50, Y: 50, Width: 20, Height: 20}})
+
+	opts := WithSize(100, 100)
+	opts.MinNodeSize = 1
+
+	svg := RenderToSVG(root, opts)
+	if n := strings.Count(svg, `width="1.00" height="1.00"`); n != 10 {
+		t.Errorf("Expected 10 aggregate cells, got %d:\n%s", n, svg)
+	}
+	if strings.Contains(svg, `width="0.10"`) {
+		t.Errorf("Expected tiny nodes to be collapsed:\n%s", svg)
+	}
+	if !strings.Contains(svg, `<rect x="50.00" y="50.00" width="20.00" height="20.00"`) {
+		t.Errorf("Expected large nodes to be drawn:\n%s", svg)
+	}
+
+	// The size is in output pixels: at 2x, the dots cover half a pixel cell
+	opts.ViewBox = "0 0 50 50"
+	svg = RenderToSVG(root, opts)
+	if !strings.Contains(svg, `width="0.50" height="0.50"`) {
+		t.Errorf("Expected cells scaled to the viewBox:\n%s", svg)
+	}
+}
+
+func TestRenderMinNodeSizeKeepsOrder(t *testing.T) {
+	root := &layout.Node{
+		Rect: layout.Rect{Width: 100, Height: 100},
+		Children: []*layout.Node{
+			{Rect: layout.Rect{X: 10, Y: 10, Width: 0.1, Height: 0.1}},
+			{Rect: layout.Rect{Width: 50, Height: 50}},
+			{Rect: layout.Rect{X: 60, Y: 60, Width: 0.1, Height: 0.1}},
+		},
+	}
+	opts := WithSize(100, 100)
+	opts.MinNodeSize = 1
+
+	// Cells are drawn where their first node was, so a tiny node before a
+	// large sibling stays below it
+	svg := RenderToSVG(root, opts)
+	before := strings.Index(svg, `<rect x="10.00" y="10.00" width="1.00"`)
+	large := strings.Index(svg, `<rect x="0.00" y="0.00" width="50.00"`)
+	after := strings.Index(svg, `<rect x="60.00" y="60.00" width="1.00"`)
+	if before < 0 || large < before || after < large {
+		t.Errorf("Expected cells in document order:\n%s", svg)
+	}
+}
+
+func TestParseViewBox(t *testing.T) {
+	tests := []struct {
+		viewBox  string
+		expected Box
+		ok       bool
+	}{
+		{"0 0 100 50", Box{Width: 100, Height: 50}, true},
+		{"-10,5, 20 30", Box{X: -10, Y: 5, Width: 20, Height: 30}, true},
+		{"", Box{}, false},
+		{"0 0 0 10", Box{}, false},
+		{"a b c d", Box{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseViewBox(tt.viewBox)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("parseViewBox(%q) = %v, %v; want %v, %v", tt.viewBox, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package svg
+
+import (
+	"fmt"
+
+	"github.com/SCKelemen/layout"
+	"github.com/SCKelemen/units"
+)
+
+// Overlay colors used by browser devtools for the box model
+const (
+	debugMarginColor   = "rgba(246, 178, 107, 0.66)"
+	debugBorderColor   = "rgba(255, 229, 153, 0.66)"
+	debugPaddingColor  = "rgba(147, 196, 125, 0.55)"
+	debugContentColor  = "rgba(111, 168, 220, 0.66)"
+	debugGapColor      = "rgba(190, 115, 255, 0.35)"
+	debugBaselineColor = "#ff00ff"
+	debugOutlineColor  = "#1a73e8"
+	debugLabelColor    = "#1a1a1a"
+)
+
+// debugOverlay returns the overlay elements for a node: its margin, border,
+// padding and content areas, a size label, and baseline and gap guides
+func (r *Renderer) debugOverlay(node *layout.Node) []Element {
+	m := r.BoxModel(node)
+	if m.Border.Width <= 0 && m.Border.Height <= 0 {
+		return nil
+	}
+
+	var elements []Element
+	for _, area := range []struct {
+		outer, inner Box
+		color        string
+	}{
+		{m.Margin, m.Border, debugMarginColor},
+		{m.Border, m.Padding, debugBorderColor},
+		{m.Padding, m.Content, debugPaddingColor},
+	} {
+		if area.outer != area.inner {
+			elements = append(elements, debugRing(area.outer, area.inner, area.color))
+		}
+	}
+	elements = append(elements,
+		&RectElement{X: m.Content.X, Y: m.Content.Y, Width: m.Content.Width, Height: m.Content.Height, Style: Style{Fill: debugContentColor}},
+		&RectElement{X: m.Border.X, Y: m.Border.Y, Width: m.Border.Width, Height: m.Border.Height, Style: Style{Fill: "none", Stroke: debugOutlineColor, StrokeWidth: 1}},
+	)
+
+	// Baseline guide
+	if node.Baseline > 0 {
+		y := m.Border.Y + node.Baseline
+		elements = append(elements, &LineElement{
+			X1: m.Border.X, Y1: y, X2: m.Border.X + m.Border.Width, Y2: y,
+			Style: Style{Stroke: debugBaselineColor, StrokeWidth: 1, StrokeDashArray: "4,2"},
+		})
+	}
+
+	// Gap guides between consecutive children
+	if hasGap(node.Style) {
+		for i := 1; i < len(node.Children); i++ {
+			prev, next := r.BoxModel(node.Children[i-1]).Margin, r.BoxModel(node.Children[i]).Margin
+			if gap, ok := gapBetween(prev, next); ok {
+				elements = append(elements, &RectElement{X: gap.X, Y: gap.Y, Width: gap.Width, Height: gap.Height, Style: Style{Fill: debugGapColor}})
+			}
+		}
+	}
+
+	// Label with the computed geometry
+	label := fmt.Sprintf("x=%s y=%s w=%s h=%s",
+		formatDimension(m.Border.X), formatDimension(m.Border.Y),
+		formatDimension(m.Border.Width), formatDimension(m.Border.Height))
+	elements = append(elements, &TextElement{
+		X:       m.Border.X + 2,
+		Y:       m.Border.Y + 10,
+		Content: label,
+		Style:   Style{Fill: debugLabelColor, FontFamily: "monospace", FontSize: units.Px(9)},
+		Attrs:   []Attr{{"stroke", "#ffffff"}, {"stroke-width", "2"}, {"paint-order", "stroke"}},
+	})
+
+	return elements
+}
+
+// debugOverlayGroup wraps the collected overlay elements, if any
+func (r *Renderer) debugOverlayGroup() string {
+	if len(r.debug) == 0 {
+		return ""
+	}
+	return Serialize(&GroupElement{
+		Attrs:    []Attr{{"class", "debug-overlay"}, {"pointer-events", "none"}},
+		Elements: r.debug,
+	}) + "\n"
+}
+
+// transformed wraps elements in a group with a transform, if there is one
+func transformed(transform string, elements []Element) []Element {
+	if transform == "" || len(elements) == 0 {
+		return elements
+	}
+	return []Element{&GroupElement{Transform: transform, Elements: elements}}
+}
+
+// debugRing fills the area between an outer and an inner box
+func debugRing(outer, inner Box, color string) Element {
+	d := RectPath(outer.X, outer.Y, outer.Width, outer.Height) + " " + RectPath(inner.X, inner.Y, inner.Width, inner.Height)
+	return &PathElement{D: d, Style: Style{Fill: color}, Attrs: []Attr{{"fill-rule", "evenodd"}}}
+}
+
+// hasGap reports whether a flex or grid container sets a gap
+func hasGap(s layout.Style) bool {
+	for _, gap := range []layout.Length{s.FlexGap, s.FlexRowGap, s.FlexColumnGap, s.GridGap, s.GridRowGap, s.GridColumnGap} {
+		if gap.Value > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// gapBetween returns the space between two boxes that follow each other
+// horizontally or vertically, spanning the range where they overlap
+func gapBetween(a, b Box) (Box, bool) {
+	top, bottom := max(a.Y, b.Y), min(a.Y+a.Height, b.Y+b.Height)
+	if right := a.X + a.Width; b.X > right && bottom > top {
+		return Box{X: right, Y: top, Width: b.X - right, Height: bottom - top}, true
+	}
+	left, end := max(a.X, b.X), min(a.X+a.Width, b.X+b.Width)
+	if below := a.Y + a.Height; b.Y > below && end > left {
+		return Box{X: left, Y: below, Width: end - left, Height: b.Y - below}, true
+	}
+	return Box{}, false
+}
@@ -0,0 +1,76 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestDebugOverlay(t *testing.T) {
+	root := &layout.Node{
+		Style: layout.Style{
+			Display:       layout.DisplayFlex,
+			FlexDirection: layout.FlexDirectionRow,
+			FlexGap:       layout.Px(10),
+			Padding:       layout.Spacing{Top: layout.Px(5), Right: layout.Px(5), Bottom: layout.Px(5), Left: layout.Px(5)},
+			Width:         layout.Px(200),
+			Height:        layout.Px(60),
+		},
+		Children: []*layout.Node{
+			{Style: layout.Style{Width: layout.Px(50), Height: layout.Px(50)}},
+			{Style: layout.Style{Width: layout.Px(50), Height: layout.Px(50)}},
+		},
+	}
+	layout.Layout(root, layout.Loose(200, 60), &layout.LayoutContext{ViewportWidth: 200, ViewportHeight: 60, RootFontSize: 16})
+	root.Baseline = 40
+
+	opts := DefaultOptions()
+	opts.Debug = true
+	svg := RenderToSVG(root, opts)
+
+	for _, expected := range []string{
+		`<g class="debug-overlay" pointer-events="none">`,
+		`M 0.00 0.00 H 120.00 V 60.00 H 0.00 Z M 5.00 5.00 H 115.00 V 55.00 H 5.00 Z" fill="` + debugPaddingColor + `"`,
+		debugContentColor,
+		">x=0 y=0 w=120 h=60</text>",
+		">x=5 y=5 w=50 h=50</text>",
+		`<rect x="55.00" y="5.00" width="10.00" height="50.00" fill="` + debugGapColor + `"/>`,
+		`<line x1="0.00" y1="40.00" x2="120.00" y2="40.00" stroke="` + debugBaselineColor + `"`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, svg)
+		}
+	}
+
+	// The overlay is drawn over the content
+	if strings.Index(svg, "debug-overlay") < strings.Index(svg, `<rect x="65.00"`) {
+		t.Error("Expected the overlay after the content")
+	}
+
+	if svg := RenderToSVG(root, DefaultOptions()); strings.Contains(svg, "debug-overlay") {
+		t.Error("Overlay should only be drawn in debug mode")
+	}
+}
+
+func TestDebugOverlayTransformed(t *testing.T) {
+	root := &layout.Node{
+		Rect:     layout.Rect{Width: 100, Height: 100},
+		Style:    layout.Style{Transform: layout.Transform{A: 1, D: 1, E: 50, F: 50}},
+		Children: []*layout.Node{{Rect: layout.Rect{X: 10, Y: 10, Width: 20, Height: 20}}},
+	}
+	opts := DefaultOptions()
+	opts.Debug = true
+	svg := RenderToSVG(root, opts)
+
+	overlay := svg[strings.Index(svg, "debug-overlay"):]
+	transform := `<g transform="matrix(1,0,0,1,50,50)">`
+	if !strings.Contains(overlay, transform) {
+		t.Fatalf("Expected the overlay to follow the node transform:\n%s", overlay)
+	}
+	for _, label := range []string{">x=0 y=0 w=100 h=100</text>", ">x=10 y=10 w=20 h=20</text>"} {
+		if i := strings.Index(overlay, label); i < strings.Index(overlay, transform) {
+			t.Errorf("Expected %q inside the transformed group:\n%s", label, overlay)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Corners holds a radius for each corner of a box
+type Corners struct {
+	TopLeft, TopRight, BottomRight, BottomLeft float64
+}
+
+// UniformCorners returns corners that all have radius r
+func UniformCorners(r float64) Corners {
+	return Corners{r, r, r, r}
+}
+
+// IsZero reports whether no corner is rounded
+func (c Corners) IsZero() bool {
+	return c == Corners{}
+}
+
+// BorderColors holds a color for each side of a box
+type BorderColors struct {
+	Top, Right, Bottom, Left string
+}
+
+// UniformBorderColor returns border colors that are all color
+func UniformBorderColor(color string) BorderColors {
+	return BorderColors{color, color, color, color}
+}
+
+// BoxShadow is a CSS-style drop shadow drawn behind a box
+type BoxShadow struct {
+	OffsetX, OffsetY float64
+	Blur             float64 // Blur radius; the Gaussian standard deviation is half of it
+	Spread           float64 // Grows (or, if negative, shrinks) the shadow shape
+	Color            string
+	Opacity          float64 // 0 means opaque
+}
+
+// BoxStyle decorates a layout node like a CSS box
+type BoxStyle struct {
+	// Background fills the border box; if empty, the node style's Fill is used
+	Background string
+
+	// BackgroundGradient fills the border box instead of Background (optional)
+	// An ID is generated if it has none
+	BackgroundGradient *LinearGradientDef
+
+	// BorderColor colors each side; empty sides use the node style's Stroke
+	BorderColor BorderColors
+
+	// BorderWidth overrides the widths from the node's Style.Border (optional)
+	BorderWidth *Edges
+
+	// Radius rounds each corner of the border box
+	Radius Corners
+
+	// Shadows are painted behind the box; the first shadow is on top
+	Shadows []BoxShadow
+
+	// Overflow hidden clips the node's children (see OverflowHidden)
+	Overflow Overflow
+}
+
+// boxStyle returns the box decorations of a node from BoxFunc
+func (r *Renderer) boxStyle(node *layout.Node, depth int) (BoxStyle, bool) {
+	if r.options.BoxFunc == nil {
+		return BoxStyle{}, false
+	}
+	return r.options.BoxFunc(node, depth)
+}
+
+// boxElements renders the decorations of a node's box
+// Undecorated nodes are drawn as a plain rectangle
+func (r *Renderer) boxElements(node *layout.Node, box BoxStyle, decorated bool, style Style) []Element {
+	rect := node.Rect
+	if !decorated {
+		return []Element{&RectElement{X: rect.X, Y: rect.Y, Width: rect.Width, Height: rect.Height, Style: r.classStyle(style)}}
+	}
+
+	model := r.BoxModel(node)
+	widths := model.BorderWidths
+	if box.BorderWidth != nil {
+		widths = *box.BorderWidth
+	}
+	border := model.Border
+	radii := clampCorners(box.Radius, border)
+
+	var elements []Element
+
+	// Shadows are painted in reverse order, so the first one ends up on top
+	for i := len(box.Shadows) - 1; i >= 0; i-- {
+		elements = append(elements, r.shadowElement(box.Shadows[i], border, radii))
+	}
+
+	// Background
+	background := style
+	if box.Background != "" {
+		background.Fill = box.Background
+	}
+	if box.BackgroundGradient != nil {
+		def := *box.BackgroundGradient
+		if def.ID == "" {
+			def.ID = r.ids.Next("gradient")
+		} else {
+			r.ids.Reserve(def.ID)
+		}
+		r.addDef(&LinearGradientElement{Def: def})
+		background.Fill = GradientURL(def.ID)
+	}
+	hasBorder := widths != Edges{}
+	if hasBorder {
+		// The border is drawn as its own shape below
+		background.Stroke, background.StrokeWidth = "", 0
+	}
+	elements = append(elements, boxShape(border, radii, r.classStyle(background)))
+
+	// Border
+	if hasBorder {
+		colors := box.BorderColor
+		for _, c := range []*string{&colors.Top, &colors.Right, &colors.Bottom, &colors.Left} {
+			if *c == "" {
+				*c = style.Stroke
+			}
+		}
+		if element := r.borderElement(border, widths, radii, colors); element != nil {
+			elements = append(elements, element)
+		}
+	}
+
+	return elements
+}
+
+// contentClipPath registers a clip path for a node's content box and returns
+// its ID. Rounded corners follow the border radius, reduced by the border and
+// padding widths as in CSS.
+func (r *Renderer) contentClipPath(node *layout.Node, box BoxStyle) string {
+	model := r.BoxModel(node)
+	widths := model.BorderWidths
+	if box.BorderWidth != nil {
+		widths = *box.BorderWidth
+	}
+	padding := model.Border.Inset(widths)
+	content := padding.Inset(model.PaddingWidths)
+
+	radii := clampCorners(box.Radius, model.Border)
+	if radii.IsZero() {
+		return r.clips.AddRect(content.X, content.Y, content.Width, content.Height)
+	}
+
+	// Distance from each side of the border box to the content box
+	inset := Edges{
+		Top:    content.Y - model.Border.Y,
+		Right:  (model.Border.X + model.Border.Width) - (content.X + content.Width),
+		Bottom: (model.Border.Y + model.Border.Height) - (content.Y + content.Height),
+		Left:   content.X - model.Border.X,
+	}
+	return r.clips.AddCustom(Serialize(&PathElement{D: roundedPath(content, insetRadii(radii, inset))}))
+}
+
+// shadowElement renders a blurred copy of the box shape
+func (r *Renderer) shadowElement(shadow BoxShadow, border Box, radii Corners) Element {
+	spread := Edges{shadow.Spread, shadow.Spread, shadow.Spread, shadow.Spread}
+	shape := border.Outset(spread)
+	shape.X += shadow.OffsetX
+	shape.Y += shadow.OffsetY
+	if !radii.IsZero() {
+		radii = Corners{
+			TopLeft:     math.Max(0, radii.TopLeft+shadow.Spread),
+			TopRight:    math.Max(0, radii.TopRight+shadow.Spread),
+			BottomRight: math.Max(0, radii.BottomRight+shadow.Spread),
+			BottomLeft:  math.Max(0, radii.BottomLeft+shadow.Spread),
+		}
+	}
+
+	style := Style{Fill: shadow.Color}
+	if shadow.Opacity > 0 && shadow.Opacity < 1 {
+		style.FillOpacity = shadow.Opacity
+	}
+	element := boxShape(shape, clampCorners(radii, shape), style)
+
+	if shadow.Blur <= 0 {
+		return element
+	}
+	stdDeviation := shadow.Blur / 2
+	extent := 3 * stdDeviation // Covers the visible extent of the blur
+	id := r.ids.Next("shadow")
+	r.addDef(BlurFilter(id, shape.Outset(Edges{extent, extent, extent, extent}), stdDeviation))
+	return &GroupElement{
+		Attrs:    []Attr{{"filter", URL(id)}},
+		Elements: []Element{element},
+	}
+}
+
+// borderElement renders the border as the area between the border box and
+// the padding box. Sides of one color form a single even-odd path; mixed
+// colors are drawn as mitered trapezoids clipped to the rounded border area.
+func (r *Renderer) borderElement(border Box, widths Edges, radii Corners, colors BorderColors) Element {
+	ring := borderRingPath(border, widths, radii)
+
+	if colors.Top == colors.Right && colors.Top == colors.Bottom && colors.Top == colors.Left {
+		if colors.Top == "" {
+			return nil
+		}
+		return &PathElement{D: ring, Style: r.classStyle(Style{Fill: colors.Top}), Attrs: []Attr{{"fill-rule", "evenodd"}}}
+	}
+
+	x, y, w, h := border.X, border.Y, border.Width, border.Height
+	inner := border.Inset(widths)
+	sides := []struct {
+		width  float64
+		color  string
+		points []Point
+	}{
+		{widths.Top, colors.Top, []Point{{x, y}, {x + w, y}, {inner.X + inner.Width, inner.Y}, {inner.X, inner.Y}}},
+		{widths.Right, colors.Right, []Point{{x + w, y}, {x + w, y + h}, {inner.X + inner.Width, inner.Y + inner.Height}, {inner.X + inner.Width, inner.Y}}},
+		{widths.Bottom, colors.Bottom, []Point{{x + w, y + h}, {x, y + h}, {inner.X, inner.Y + inner.Height}, {inner.X + inner.Width, inner.Y + inner.Height}}},
+		{widths.Left, colors.Left, []Point{{x, y + h}, {x, y}, {inner.X, inner.Y}, {inner.X, inner.Y + inner.Height}}},
+	}
+
+	group := &GroupElement{}
+	for _, side := range sides {
+		if side.width <= 0 || side.color == "" {
+			continue
+		}
+		group.Elements = append(group.Elements, &PolygonElement{Points: side.points, Style: r.classStyle(Style{Fill: side.color})})
+	}
+	if len(group.Elements) == 0 {
+		return nil
+	}
+	if !radii.IsZero() {
+		clipID := r.clips.AddCustom(fmt.Sprintf(`<path d="%s" clip-rule="evenodd"/>`, ring))
+		group.Style.ClipPath = URL(clipID)
+	}
+	return group
+}
+
+// classStyle moves a style into a generated class when deduplication is on
+func (r *Renderer) classStyle(style Style) Style {
+	if r.classes != nil {
+		return r.classes.Class(style)
+	}
+	return style
+}
+
+// addDef adds a definition to <defs>, unless one with its ID was already added
+func (r *Renderer) addDef(def Element) {
+	if id := elementID(def); id != "" {
+		if r.defIDs[id] {
+			return
+		}
+		if r.defIDs == nil {
+			r.defIDs = make(map[string]bool)
+		}
+		r.defIDs[id] = true
+	}
+	r.defs = append(r.defs, def)
+}
+
+// generatedDefs returns the definitions generated while rendering as <defs> content
+func (r *Renderer) generatedDefs() string {
+	var b strings.Builder
+	for _, def := range r.defs {
+		def.WriteTo(&b)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// boxShape returns a rect, or a path when corners have different radii
+func boxShape(b Box, radii Corners, style Style) Element {
+	r := radii.TopLeft
+	if radii == UniformCorners(r) {
+		return &RectElement{X: b.X, Y: b.Y, Width: b.Width, Height: b.Height, RX: r, RY: r, Style: style}
+	}
+	return &PathElement{D: RoundedBoxPath(b, radii), Style: style}
+}
+
+// RoundedBoxPath returns path data for a box with a radius per corner
+func RoundedBoxPath(b Box, radii Corners) string {
+	return roundedPath(b, [4]Point{
+		{radii.TopLeft, radii.TopLeft},
+		{radii.TopRight, radii.TopRight},
+		{radii.BottomRight, radii.BottomRight},
+		{radii.BottomLeft, radii.BottomLeft},
+	})
+}
+
+// borderRingPath returns the area between the border box and the padding box
+func borderRingPath(border Box, widths Edges, radii Corners) string {
+	return RoundedBoxPath(border, radii) + " " + roundedPath(border.Inset(widths), insetRadii(radii, widths))
+}
+
+// insetRadii returns the elliptical radii of corners moved inward by inset
+func insetRadii(radii Corners, inset Edges) [4]Point {
+	return [4]Point{
+		{math.Max(0, radii.TopLeft-inset.Left), math.Max(0, radii.TopLeft-inset.Top)},
+		{math.Max(0, radii.TopRight-inset.Right), math.Max(0, radii.TopRight-inset.Top)},
+		{math.Max(0, radii.BottomRight-inset.Right), math.Max(0, radii.BottomRight-inset.Bottom)},
+		{math.Max(0, radii.BottomLeft-inset.Left), math.Max(0, radii.BottomLeft-inset.Bottom)},
+	}
+}
+
+// roundedPath draws a box clockwise with elliptical corners, given as
+// (rx, ry) pairs from the top-left corner clockwise
+func roundedPath(b Box, radii [4]Point) string {
+	for i, r := range radii {
+		if r.X <= 0 || r.Y <= 0 {
+			radii[i] = Point{} // A corner with a zero radius on either axis is square
+		}
+	}
+	tl, tr, br, bl := radii[0], radii[1], radii[2], radii[3]
+	right, bottom := b.X+b.Width, b.Y+b.Height
+
+	pb := NewPathBuilder()
+	pb.MoveTo(b.X+tl.X, b.Y)
+	pb.HorizontalLineTo(right - tr.X)
+	if tr.X > 0 {
+		pb.ArcTo(tr.X, tr.Y, 0, 0, 1, right, b.Y+tr.Y)
+	}
+	pb.VerticalLineTo(bottom - br.Y)
+	if br.X > 0 {
+		pb.ArcTo(br.X, br.Y, 0, 0, 1, right-br.X, bottom)
+	}
+	pb.HorizontalLineTo(b.X + bl.X)
+	if bl.X > 0 {
+		pb.ArcTo(bl.X, bl.Y, 0, 0, 1, b.X, bottom-bl.Y)
+	}
+	pb.VerticalLineTo(b.Y + tl.Y)
+	if tl.X > 0 {
+		pb.ArcTo(tl.X, tl.Y, 0, 0, 1, b.X+tl.X, b.Y)
+	}
+	pb.Close()
+	return pb.String()
+}
+
+// clampCorners scales radii down so adjacent corners never overlap,
+// as CSS does for border-radius
+func clampCorners(c Corners, b Box) Corners {
+	scale := 1.0
+	for _, side := range []struct{ length, sum float64 }{
+		{b.Width, c.TopLeft + c.TopRight},
+		{b.Width, c.BottomLeft + c.BottomRight},
+		{b.Height, c.TopLeft + c.BottomLeft},
+		{b.Height, c.TopRight + c.BottomRight},
+	} {
+		if side.sum > 0 && side.length/side.sum < scale {
+			scale = math.Max(0, side.length/side.sum)
+		}
+	}
+	if scale == 1 {
+		return c
+	}
+	return Corners{c.TopLeft * scale, c.TopRight * scale, c.BottomRight * scale, c.BottomLeft * scale}
+}
@@ -0,0 +1,217 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestRenderBoxDecorations(t *testing.T) {
+	uniform := layout.Spacing{Top: layout.Px(2), Right: layout.Px(2), Bottom: layout.Px(2), Left: layout.Px(2)}
+
+	tests := []struct {
+		name     string
+		border   layout.Spacing
+		box      BoxStyle
+		expected []string
+		excluded []string
+	}{
+		{
+			name:     "background and uniform radius",
+			box:      BoxStyle{Background: "#ffffff", Radius: UniformCorners(6)},
+			expected: []string{`<rect x="10.00" y="10.00" width="100.00" height="50.00" rx="6.00" ry="6.00" fill="#ffffff"`},
+		},
+		{
+			name:     "per-corner radii are drawn as a path",
+			box:      BoxStyle{Background: "#ffffff", Radius: Corners{TopLeft: 8}},
+			expected: []string{`<path d="M 18.00 10.00 H 110.00 V 60.00 H 10.00 V 18.00 A 8.00 8.00 0.00 0 1 18.00 10.00 Z" fill="#ffffff"`},
+		},
+		{
+			name:     "single color border is an even-odd ring",
+			border:   uniform,
+			box:      BoxStyle{BorderColor: UniformBorderColor("#333333")},
+			expected: []string{`fill="#333333" fill-rule="evenodd"`, "M 12.00 12.00 H 108.00"},
+			excluded: []string{"<polygon"},
+		},
+		{
+			name:   "mixed border colors are drawn per side",
+			border: uniform,
+			box: BoxStyle{
+				BorderColor: BorderColors{Top: "red", Right: "green", Bottom: "blue", Left: "black"},
+				Radius:      UniformCorners(4),
+			},
+			expected: []string{
+				`<polygon points="10.00,10.00 110.00,10.00 108.00,12.00 12.00,12.00" fill="red"/>`,
+				`fill="black"`,
+				`clip-path="url(#clip-`,
+				`clip-rule="evenodd"`,
+			},
+		},
+		{
+			name:     "border width override",
+			box:      BoxStyle{BorderColor: UniformBorderColor("red"), BorderWidth: &Edges{Bottom: 3}},
+			expected: []string{"M 10.00 10.00 H 110.00 V 57.00 H 10.00 V 10.00 Z"},
+		},
+		{
+			name: "gradient background",
+			box: BoxStyle{BackgroundGradient: &LinearGradientDef{
+				X2:    "100%",
+				Stops: []GradientStop{{Offset: "0%", Color: "red"}, {Offset: "100%", Color: "blue"}},
+			}},
+			expected: []string{`<linearGradient id="gradient-`, `fill="url(#gradient-`},
+		},
+		{
+			name:     "blurred shadow",
+			box:      BoxStyle{Shadows: []BoxShadow{{OffsetY: 2, Blur: 4, Spread: 1, Color: "#000000", Opacity: 0.25}}},
+			expected: []string{`<filter id="shadow-`, `filterUnits="userSpaceOnUse"`, `stdDeviation="2.00"`, `<g filter="url(#shadow-`, `<rect x="9.00" y="11.00" width="102.00" height="52.00" fill="#000000" fill-opacity="0.25"/>`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &layout.Node{
+				Rect:  layout.Rect{X: 10, Y: 10, Width: 100, Height: 50},
+				Style: layout.Style{Border: tt.border},
+			}
+			opts := DefaultOptions()
+			opts.BoxFunc = func(node *layout.Node, depth int) (BoxStyle, bool) {
+				return tt.box, true
+			}
+
+			svg := RenderToSVG(node, opts)
+			for _, expected := range tt.expected {
+				if !strings.Contains(svg, expected) {
+					t.Errorf("Expected %q in:\n%s", expected, svg)
+				}
+			}
+			for _, excluded := range tt.excluded {
+				if strings.Contains(svg, excluded) {
+					t.Errorf("Did not expect %q in:\n%s", excluded, svg)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSharedGradient(t *testing.T) {
+	root := &layout.Node{
+		Rect: layout.Rect{Width: 100, Height: 100},
+		Children: []*layout.Node{
+			{Rect: layout.Rect{Width: 50, Height: 50}},
+			{Rect: layout.Rect{X: 50, Width: 50, Height: 50}},
+		},
+	}
+	gradient := &LinearGradientDef{ID: "bg", X2: "100%", Stops: []GradientStop{{Offset: "0%", Color: "red"}, {Offset: "100%", Color: "blue"}}}
+	opts := DefaultOptions()
+	opts.BoxFunc = func(n *layout.Node, depth int) (BoxStyle, bool) {
+		return BoxStyle{BackgroundGradient: gradient}, depth == 1
+	}
+
+	svg := RenderToSVG(root, opts)
+	if n := strings.Count(svg, `<linearGradient id="bg"`); n != 1 {
+		t.Errorf("Expected the named gradient once, got %d:\n%s", n, svg)
+	}
+	if n := strings.Count(svg, `fill="url(#bg)"`); n != 2 {
+		t.Errorf("Expected both nodes to use the gradient, got %d:\n%s", n, svg)
+	}
+}
+
+func TestClampCorners(t *testing.T) {
+	got := clampCorners(UniformCorners(40), Box{Width: 100, Height: 40})
+	if got != UniformCorners(20) {
+		t.Errorf("clampCorners = %+v, want radius 20", got)
+	}
+	if got := clampCorners(UniformCorners(5), Box{Width: 100, Height: 40}); got != UniformCorners(5) {
+		t.Errorf("small radii should be kept, got %+v", got)
+	}
+}
+
+func TestRenderOverflowHidden(t *testing.T) {
+	child := &layout.Node{Rect: layout.Rect{X: 50, Y: 50, Width: 200, Height: 200}}
+
+	tests := []struct {
+		name     string
+		box      BoxStyle
+		expected []string
+		excluded []string
+	}{
+		{
+			name:     "visible overflow does not clip",
+			box:      BoxStyle{},
+			excluded: []string{"clip-path", "<clipPath"},
+		},
+		{
+			name: "hidden overflow clips to the content box",
+			box:  BoxStyle{Overflow: OverflowHidden},
+			expected: []string{
+				`<g clip-path="url(#clip-`,
+				`<rect x="14.00" y="14.00" width="92.00" height="92.00"/></clipPath>`,
+			},
+		},
+		{
+			name: "rounded nodes clip with inner radii",
+			box:  BoxStyle{Overflow: OverflowHidden, Radius: UniformCorners(10)},
+			expected: []string{
+				`<path d="M 20.00 14.00 H 100.00 A 6.00 6.00 0.00 0 1 106.00 20.00`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &layout.Node{
+				Rect: layout.Rect{X: 10, Y: 10, Width: 100, Height: 100},
+				Style: layout.Style{
+					Border:  layout.Spacing{Top: layout.Px(1), Right: layout.Px(1), Bottom: layout.Px(1), Left: layout.Px(1)},
+					Padding: layout.Spacing{Top: layout.Px(3), Right: layout.Px(3), Bottom: layout.Px(3), Left: layout.Px(3)},
+				},
+				Children: []*layout.Node{child},
+			}
+			opts := DefaultOptions()
+			opts.BoxFunc = func(n *layout.Node, depth int) (BoxStyle, bool) {
+				if n != node {
+					return BoxStyle{}, false
+				}
+				return tt.box, true
+			}
+
+			svg := RenderToSVG(node, opts)
+			for _, expected := range tt.expected {
+				if !strings.Contains(svg, expected) {
+					t.Errorf("Expected %q in:\n%s", expected, svg)
+				}
+			}
+			for _, excluded := range tt.excluded {
+				if strings.Contains(svg, excluded) {
+					t.Errorf("Did not expect %q in:\n%s", excluded, svg)
+				}
+			}
+
+			// The child is drawn inside the clipped group
+			if tt.box.Overflow == OverflowHidden {
+				clip := strings.Index(svg, `<g clip-path=`)
+				rect := strings.Index(svg, `<rect x="50.00"`)
+				if clip < 0 || rect < clip {
+					t.Errorf("Expected child inside the clip group:\n%s", svg)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderNodesClipDefs(t *testing.T) {
+	node := &layout.Node{
+		Rect:     layout.Rect{Width: 50, Height: 50},
+		Children: []*layout.Node{{Rect: layout.Rect{Width: 100, Height: 100}}},
+	}
+	opts := DefaultOptions()
+	opts.BoxFunc = func(n *layout.Node, depth int) (BoxStyle, bool) {
+		return BoxStyle{Overflow: OverflowHidden}, depth == 0
+	}
+
+	svg := RenderNodes([]*layout.Node{node}, opts)
+	if !strings.Contains(svg, "<clipPath") || !strings.Contains(svg, `clip-path="url(#clip-`) {
+		t.Errorf("Expected clip path definitions in:\n%s", svg)
+	}
+}
@@ -0,0 +1,318 @@
+package svg
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Document builds a complete SVG document and manages its <defs>
+//
+// Definitions added with AddDef are deduplicated, and every id used in the
+// document is tracked so that generated IDs never collide. When written, the
+// document checks that each url(#id) and href="#id" reference resolves to an
+// element that is actually emitted.
+type Document struct {
+	// Prolog holds nodes written before the root element, such as an
+	// XML declaration, DOCTYPE or leading comments
+	Prolog []Element
+
+	// Epilog holds comments and processing instructions written after the
+	// root element
+	Epilog []Element
+
+	// Name is the root element name, e.g. "svg:svg" for a prefixed root
+	// (optional, defaults to "svg")
+	Name string
+
+	// Attrs are the attributes of the root <svg> element
+	Attrs []Attr
+
+	// StyleSheet is written at the start of <defs> (optional)
+	StyleSheet *StyleSheet
+
+	// Elements are the content elements, in document order
+	Elements []Element
+
+	// IDs numbers the IDs returned by NewID (optional)
+	// If nil, IDs are numbered per document without a prefix
+	IDs *IDAllocator
+
+	defs    []Element
+	defKeys map[string]string // dedup key -> def ID
+	ids     map[string]bool
+}
+
+// NewDocument creates a document with the given dimensions
+func NewDocument(width, height float64) *Document {
+	return &Document{
+		Attrs: []Attr{
+			{"width", formatDimension(width)},
+			{"height", formatDimension(height)},
+			{"viewBox", "0 0 " + formatDimension(width) + " " + formatDimension(height)},
+			{"xmlns", "http://www.w3.org/2000/svg"},
+		},
+	}
+}
+
+// AddDef adds a definition (gradient, marker, clipPath, ...) to the document
+// and returns the ID that references to it should use.
+//
+// If an identical definition was already added, the existing ID is returned
+// and the new definition is dropped. Adding a different definition under an
+// ID that is already in use is an error.
+func (d *Document) AddDef(def Element) (string, error) {
+	d.init()
+
+	id := elementID(def)
+	if id == "" {
+		return "", fmt.Errorf("def <%s> has no id", def.Tag())
+	}
+
+	key := defKey(def)
+	if existing, ok := d.defKeys[key]; ok {
+		return existing, nil
+	}
+	if d.ids[id] {
+		return "", fmt.Errorf("duplicate id: %s", id)
+	}
+
+	d.defs = append(d.defs, def)
+	d.defKeys[key] = id
+	d.ids[id] = true
+	return id, nil
+}
+
+// AddClipPaths adds every clipPath registered with a ClipPathManager as a def
+func (d *Document) AddClipPaths(m *ClipPathManager) error {
+	for _, cp := range m.Elements() {
+		if _, err := d.AddDef(cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add appends content elements to the document
+// IDs used by the elements are recorded in the ID registry
+func (d *Document) Add(elements ...Element) *Document {
+	d.init()
+	for _, e := range elements {
+		collectIDs(e, d.ids)
+	}
+	d.Elements = append(d.Elements, elements...)
+	return d
+}
+
+// Defs returns the definitions added to the document
+func (d *Document) Defs() []Element {
+	return d.defs
+}
+
+// NewID returns an ID with the given prefix that is not yet used in the document
+// The ID is reserved, so subsequent calls never return it again
+func (d *Document) NewID(prefix string) string {
+	d.init()
+	if d.IDs == nil {
+		d.IDs = NewIDAllocator("")
+	}
+	for {
+		id := d.IDs.Next(prefix)
+		if !d.ids[id] {
+			d.ids[id] = true
+			return id
+		}
+	}
+}
+
+// HasID reports whether an ID is in use in the document
+func (d *Document) HasID(id string) bool {
+	return d.ids[id]
+}
+
+// Tag returns the root element name
+func (d *Document) Tag() string {
+	if d.Name == "" {
+		return "svg"
+	}
+	return d.Name
+}
+
+// Attributes returns the root element attributes
+func (d *Document) Attributes() []Attr { return d.Attrs }
+
+// Children returns the <defs> element (if any) followed by the content
+func (d *Document) Children() []Element {
+	var defs []Element
+	if d.StyleSheet != nil {
+		defs = append(defs, Raw(d.StyleSheet.ToSVG()))
+	}
+	defs = append(defs, d.defs...)
+
+	var children []Element
+	if len(defs) > 0 {
+		children = append(children, &DefsElement{Elements: defs})
+	}
+	return append(children, d.Elements...)
+}
+
+// Validate checks that every url(#id) and href="#id" reference resolves
+func (d *Document) Validate() error {
+	ids := make(map[string]bool)
+	refs := make(map[string]bool)
+	for _, child := range d.Children() {
+		collectIDs(child, ids)
+		collectRefs(child, refs)
+	}
+
+	var missing []string
+	for ref := range refs {
+		if !ids[ref] {
+			missing = append(missing, ref)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("unresolved references: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// WriteTo validates the document and writes it to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if err := d.Validate(); err != nil {
+		return 0, err
+	}
+	return d.write(w)
+}
+
+// String returns the SVG markup for the document
+// References are not validated; use WriteTo to check them
+func (d *Document) String() string {
+	var b strings.Builder
+	d.write(&b)
+	return b.String()
+}
+
+// write writes the prolog, root element and epilog to w
+func (d *Document) write(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	for _, node := range d.Prolog {
+		cw.writeElement(node)
+		cw.writeString("\n")
+	}
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	n, err := writeElement(w, d)
+	cw.n += n
+	cw.err = err
+	for _, node := range d.Epilog {
+		cw.writeString("\n")
+		cw.writeElement(node)
+	}
+	return cw.n, cw.err
+}
+
+func (d *Document) init() {
+	if d.ids == nil {
+		d.ids = make(map[string]bool)
+	}
+	if d.defKeys == nil {
+		d.defKeys = make(map[string]string)
+	}
+}
+
+// elementID returns the value of an element's id attribute
+func elementID(e Element) string {
+	return attrValue(e.Attributes(), "id")
+}
+
+// attrValue returns the value of the named attribute, or "" if it is not set
+func attrValue(attrs []Attr, name string) string {
+	for _, attr := range attrs {
+		if attr.Name == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// defKey returns a key identifying a def by its content, ignoring its id
+func defKey(e Element) string {
+	var b strings.Builder
+	b.WriteString(e.Tag())
+	for _, attr := range e.Attributes() {
+		if attr.Name == "id" {
+			continue
+		}
+		b.WriteString(" ")
+		b.WriteString(attr.Name)
+		b.WriteString(`="`)
+		b.WriteString(attr.Value)
+		b.WriteString(`"`)
+	}
+	b.WriteString(">")
+	for _, child := range e.Children() {
+		child.WriteTo(&b)
+	}
+	return b.String()
+}
+
+var (
+	rawIDPattern  = regexp.MustCompile(`\bid="([^"]+)"`)
+	urlRefPattern = regexp.MustCompile(`url\(#([^)]+)\)`)
+	rawRefPattern = regexp.MustCompile(`href="#([^"]+)"`)
+)
+
+// collectIDs records every id attribute in an element tree
+func collectIDs(e Element, ids map[string]bool) {
+	if raw, ok := e.(Raw); ok {
+		for _, m := range rawIDPattern.FindAllStringSubmatch(string(raw), -1) {
+			ids[m[1]] = true
+		}
+		return
+	}
+	if id := elementID(e); id != "" {
+		ids[id] = true
+	}
+	for _, child := range e.Children() {
+		collectIDs(child, ids)
+	}
+}
+
+// collectRefs records every local url(#id) and href="#id" reference in an element tree
+func collectRefs(e Element, refs map[string]bool) {
+	if raw, ok := e.(Raw); ok {
+		for _, m := range urlRefPattern.FindAllStringSubmatch(string(raw), -1) {
+			refs[m[1]] = true
+		}
+		for _, m := range rawRefPattern.FindAllStringSubmatch(string(raw), -1) {
+			refs[m[1]] = true
+		}
+		return
+	}
+	for _, attr := range e.Attributes() {
+		if attr.Name == "href" || attr.Name == "xlink:href" {
+			if strings.HasPrefix(attr.Value, "#") {
+				refs[attr.Value[1:]] = true
+			}
+			continue
+		}
+		for _, m := range urlRefPattern.FindAllStringSubmatch(attr.Value, -1) {
+			refs[m[1]] = true
+		}
+	}
+	for _, child := range e.Children() {
+		// Stylesheets reference definitions from CSS, e.g. fill: url(#a)
+		if text, ok := child.(CharData); ok && localName(e.Tag()) == "style" {
+			for _, m := range urlRefPattern.FindAllStringSubmatch(string(text), -1) {
+				refs[m[1]] = true
+			}
+			continue
+		}
+		collectRefs(child, refs)
+	}
+}
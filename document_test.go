@@ -0,0 +1,89 @@
+package svg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDocumentAddDefDeduplicates(t *testing.T) {
+	doc := NewDocument(100, 100)
+
+	id1, err := doc.AddDef(&LinearGradientElement{Def: LinearGradientDef{ID: "fade", Stops: []GradientStop{{Offset: "0%", Color: "#000"}}}})
+	if err != nil {
+		t.Fatalf("AddDef failed: %v", err)
+	}
+
+	// Identical content under another ID resolves to the first def
+	id2, err := doc.AddDef(&LinearGradientElement{Def: LinearGradientDef{ID: "fade-copy", Stops: []GradientStop{{Offset: "0%", Color: "#000"}}}})
+	if err != nil {
+		t.Fatalf("AddDef failed: %v", err)
+	}
+	if id1 != "fade" || id2 != "fade" {
+		t.Errorf("Expected both defs to resolve to %q, got %q and %q", "fade", id1, id2)
+	}
+	if len(doc.Defs()) != 1 {
+		t.Errorf("Expected 1 def, got %d", len(doc.Defs()))
+	}
+
+	// Different content under a used ID is rejected
+	if _, err := doc.AddDef(&LinearGradientElement{Def: LinearGradientDef{ID: "fade", X1: "10%"}}); err == nil {
+		t.Error("Expected error for conflicting def ID")
+	}
+}
+
+func TestDocumentNewID(t *testing.T) {
+	doc := NewDocument(100, 100)
+	doc.Add(&RectElement{Attrs: []Attr{{"id", "clip-1"}}})
+
+	id := doc.NewID("clip")
+	if id == "clip-1" {
+		t.Error("Expected NewID to skip IDs already in the document")
+	}
+	if !doc.HasID(id) {
+		t.Errorf("Expected %q to be registered", id)
+	}
+	if doc.NewID("clip") == id {
+		t.Error("Expected NewID to return unique IDs")
+	}
+}
+
+func TestDocumentWriteTo(t *testing.T) {
+	doc := NewDocument(200, 100)
+
+	markerID, err := doc.AddDef(&MarkerElement{Def: MarkerDef{ID: "arrow", Content: `<path d="M 0 0 L 10 5 L 0 10 Z"/>`}})
+	if err != nil {
+		t.Fatalf("AddDef failed: %v", err)
+	}
+	doc.Add(&LineElement{X2: 100, Style: Style{Stroke: "#000"}, Attrs: MarkerAttrs("", "", MarkerURL(markerID))})
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	result := buf.String()
+	if !strings.HasPrefix(result, `<svg width="200" height="100" viewBox="0 0 200 100" xmlns="http://www.w3.org/2000/svg"><defs><marker id="arrow"`) {
+		t.Errorf("Expected svg root followed by defs, got:\n%s", result)
+	}
+	if strings.Index(result, "</defs>") > strings.Index(result, "<line") {
+		t.Errorf("Expected defs before content, got:\n%s", result)
+	}
+}
+
+func TestDocumentUnresolvedReference(t *testing.T) {
+	doc := NewDocument(100, 100)
+	doc.Add(&RectElement{Width: 10, Height: 10, Style: Style{Fill: GradientURL("missing"), ClipPath: URL("also-missing")}})
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	if err == nil {
+		t.Fatal("Expected error for unresolved references")
+	}
+	if !strings.Contains(err.Error(), "also-missing, missing") {
+		t.Errorf("Expected missing IDs in error, got: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Error("Expected nothing to be written for an invalid document")
+	}
+}
@@ -0,0 +1,216 @@
+package svg
+
+import (
+	"io"
+	"strings"
+)
+
+// Attr represents a single SVG attribute
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// Element is a node in an SVG element tree
+// Elements carry their attributes and children and serialize themselves via WriteTo
+type Element interface {
+	io.WriterTo
+
+	// Tag returns the element name (e.g. "rect", "g")
+	// Text, comment and raw nodes return an empty string
+	Tag() string
+
+	// Attributes returns the attributes of the element in output order
+	Attributes() []Attr
+
+	// Children returns the child nodes of the element
+	Children() []Element
+}
+
+// Serialize renders an element tree to an SVG string
+func Serialize(e Element) string {
+	var b strings.Builder
+	e.WriteTo(&b)
+	return b.String()
+}
+
+// writeElement writes the markup for a standard element and its children
+func writeElement(w io.Writer, e Element) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	cw.writeString("<")
+	cw.writeString(e.Tag())
+	for _, attr := range e.Attributes() {
+		cw.writeString(" ")
+		cw.writeString(attr.Name)
+		cw.writeString(`="`)
+		cw.writeString(escapeXML(attr.Value))
+		cw.writeString(`"`)
+	}
+
+	children := e.Children()
+	if len(children) == 0 {
+		cw.writeString("/>")
+		return cw.n, cw.err
+	}
+
+	cw.writeString(">")
+	for _, child := range children {
+		cw.writeElement(child)
+	}
+	cw.writeString("</")
+	cw.writeString(e.Tag())
+	cw.writeString(">")
+
+	return cw.n, cw.err
+}
+
+// countingWriter tracks bytes written and the first error encountered
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) writeString(s string) {
+	if cw.err != nil {
+		return
+	}
+	n, err := io.WriteString(cw.w, s)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+func (cw *countingWriter) writeElement(e Element) {
+	if cw.err != nil {
+		return
+	}
+	n, err := e.WriteTo(cw.w)
+	cw.n += n
+	cw.err = err
+}
+
+// GenericElement is an element with an arbitrary tag name and attributes
+// It is used for SVG elements that have no dedicated type
+type GenericElement struct {
+	Name     string
+	Attrs    []Attr
+	Elements []Element
+}
+
+// Tag returns the element name
+func (e *GenericElement) Tag() string { return e.Name }
+
+// Attributes returns the element attributes
+func (e *GenericElement) Attributes() []Attr { return e.Attrs }
+
+// Children returns the child nodes
+func (e *GenericElement) Children() []Element { return e.Elements }
+
+// WriteTo writes the element markup to w
+func (e *GenericElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// GroupElement represents an SVG <g> element
+type GroupElement struct {
+	Transform string
+	Style     Style
+	Attrs     []Attr
+	Elements  []Element
+}
+
+// Tag returns the element name
+func (g *GroupElement) Tag() string { return "g" }
+
+// Attributes returns the group attributes
+func (g *GroupElement) Attributes() []Attr {
+	var attrs []Attr
+	if g.Transform != "" {
+		attrs = append(attrs, Attr{"transform", g.Transform})
+	}
+	attrs = append(attrs, styleAttrs(g.Style)...)
+	return append(attrs, g.Attrs...)
+}
+
+// Children returns the grouped elements
+func (g *GroupElement) Children() []Element { return g.Elements }
+
+// WriteTo writes the group markup to w
+func (g *GroupElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, g) }
+
+// Add appends elements to the group
+func (g *GroupElement) Add(elements ...Element) *GroupElement {
+	g.Elements = append(g.Elements, elements...)
+	return g
+}
+
+// DefsElement represents an SVG <defs> element
+type DefsElement struct {
+	Elements []Element
+}
+
+// Tag returns the element name
+func (d *DefsElement) Tag() string { return "defs" }
+
+// Attributes returns no attributes
+func (d *DefsElement) Attributes() []Attr { return nil }
+
+// Children returns the definitions
+func (d *DefsElement) Children() []Element { return d.Elements }
+
+// WriteTo writes the defs markup to w
+func (d *DefsElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, d) }
+
+// CharData is a text node; its content is escaped when written
+type CharData string
+
+// Tag returns an empty string for text nodes
+func (c CharData) Tag() string { return "" }
+
+// Attributes returns no attributes
+func (c CharData) Attributes() []Attr { return nil }
+
+// Children returns no children
+func (c CharData) Children() []Element { return nil }
+
+// WriteTo writes the escaped text to w
+func (c CharData) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, escapeXML(string(c)))
+	return int64(n), err
+}
+
+// Comment is an XML comment node
+type Comment string
+
+// Tag returns an empty string for comments
+func (c Comment) Tag() string { return "" }
+
+// Attributes returns no attributes
+func (c Comment) Attributes() []Attr { return nil }
+
+// Children returns no children
+func (c Comment) Children() []Element { return nil }
+
+// WriteTo writes the comment to w
+func (c Comment) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, "<!--"+string(c)+"-->")
+	return int64(n), err
+}
+
+// Raw is pre-rendered markup that is written verbatim
+// It lets string-based helpers be mixed into an element tree
+type Raw string
+
+// Tag returns an empty string for raw markup
+func (r Raw) Tag() string { return "" }
+
+// Attributes returns no attributes
+func (r Raw) Attributes() []Attr { return nil }
+
+// Children returns no children
+func (r Raw) Children() []Element { return nil }
+
+// WriteTo writes the markup to w unchanged
+func (r Raw) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, string(r))
+	return int64(n), err
+}
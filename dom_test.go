@@ -0,0 +1,85 @@
+package svg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestElementWriteTo(t *testing.T) {
+	group := &GroupElement{Transform: "translate(10,10)"}
+	group.Add(
+		&RectElement{X: 0, Y: 0, Width: 10, Height: 20, Style: Style{Fill: "#f00"}},
+		&TextElement{X: 5, Y: 5, Content: "a < b"},
+	)
+
+	var buf bytes.Buffer
+	n, err := group.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned %d, wrote %d bytes", n, buf.Len())
+	}
+
+	expected := `<g transform="translate(10,10)">` +
+		`<rect x="0.00" y="0.00" width="10.00" height="20.00" fill="#f00"/>` +
+		`<text x="5.00" y="5.00">a &lt; b</text>` +
+		`</g>`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestElementPostProcessing(t *testing.T) {
+	rect := &RectElement{X: 1, Y: 2, Width: 3, Height: 4}
+	rect.Attrs = append(rect.Attrs, Attr{"id", "bar-1"})
+
+	group := &GroupElement{}
+	group.Add(&CircleElement{CX: 1, CY: 1, R: 1}, rect)
+
+	// Reorder children
+	group.Elements[0], group.Elements[1] = group.Elements[1], group.Elements[0]
+
+	if group.Children()[0].Tag() != "rect" {
+		t.Fatalf("Expected rect first, got %s", group.Children()[0].Tag())
+	}
+
+	result := Serialize(group)
+	if !strings.Contains(result, `id="bar-1"/>`) {
+		t.Errorf("Expected id attribute, got:\n%s", result)
+	}
+	if strings.Index(result, "<rect") > strings.Index(result, "<circle") {
+		t.Errorf("Expected rect before circle, got:\n%s", result)
+	}
+}
+
+func TestStringHelpersMatchElements(t *testing.T) {
+	style := Style{Stroke: "#000", StrokeWidth: 2}
+
+	tests := []struct {
+		name    string
+		helper  string
+		element Element
+	}{
+		{"Rect", Rect(1, 2, 3, 4, style), &RectElement{X: 1, Y: 2, Width: 3, Height: 4, Style: style}},
+		{"Circle", Circle(1, 2, 3, style), &CircleElement{CX: 1, CY: 2, R: 3, Style: style}},
+		{"Path", Path("M 0 0", style), &PathElement{D: "M 0 0", Style: style}},
+		{"Marker", ArrowMarker("a", "red"), &MarkerElement{Def: MarkerDef{
+			ID: "a", ViewBox: "0 0 10 10", RefX: 10, RefY: 5, MarkerWidth: 6, MarkerHeight: 6,
+			Orient: MarkerOrientAuto, Content: `<path d="M 0 0 L 10 5 L 0 10 Z" fill="red"/>`,
+		}}},
+		{"LinearGradient", SimpleLinearGradient("g", "#000", "#fff", 0), &LinearGradientElement{Def: LinearGradientDef{
+			ID: "g", X1: "0%", Y1: "0%", X2: "100%", Y2: "0%",
+			Stops: []GradientStop{{Offset: "0%", Color: "#000", Opacity: 1}, {Offset: "100%", Color: "#fff", Opacity: 1}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Serialize(tt.element); got != tt.helper {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.helper, got)
+			}
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package svg
 
 import (
-	"fmt"
+	"io"
 	"strings"
 
 	"github.com/SCKelemen/layout"
@@ -95,37 +95,318 @@ type Style struct {
 	FontSize         units.Length // Type-safe CSS length with units
 	FontWeight       FontWeight
 	FontStyle        FontStyle
+	Cursor           string // CSS cursor, e.g. "pointer"
+	PointerEvents    string // e.g. "none" to let clicks pass through
 }
 
+// RectElement represents an SVG <rect> element
+type RectElement struct {
+	X, Y, Width, Height float64
+	RX, RY              float64 // Corner radii (optional)
+	Style               Style
+	Attrs               []Attr
+}
+
+// Tag returns the element name
+func (e *RectElement) Tag() string { return "rect" }
+
+// Attributes returns the rectangle attributes
+func (e *RectElement) Attributes() []Attr {
+	attrs := []Attr{
+		{"x", formatFloat(e.X)},
+		{"y", formatFloat(e.Y)},
+		{"width", formatFloat(e.Width)},
+		{"height", formatFloat(e.Height)},
+	}
+	if e.RX != 0 || e.RY != 0 {
+		attrs = append(attrs, Attr{"rx", formatFloat(e.RX)}, Attr{"ry", formatFloat(e.RY)})
+	}
+	attrs = append(attrs, styleAttrs(e.Style)...)
+	return append(attrs, e.Attrs...)
+}
+
+// Children returns no children
+func (e *RectElement) Children() []Element { return nil }
+
+// WriteTo writes the rectangle markup to w
+func (e *RectElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// CircleElement represents an SVG <circle> element
+type CircleElement struct {
+	CX, CY, R float64
+	Style     Style
+	Attrs     []Attr
+}
+
+// Tag returns the element name
+func (e *CircleElement) Tag() string { return "circle" }
+
+// Attributes returns the circle attributes
+func (e *CircleElement) Attributes() []Attr {
+	attrs := []Attr{
+		{"cx", formatFloat(e.CX)},
+		{"cy", formatFloat(e.CY)},
+		{"r", formatFloat(e.R)},
+	}
+	attrs = append(attrs, styleAttrs(e.Style)...)
+	return append(attrs, e.Attrs...)
+}
+
+// Children returns no children
+func (e *CircleElement) Children() []Element { return nil }
+
+// WriteTo writes the circle markup to w
+func (e *CircleElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// EllipseElement represents an SVG <ellipse> element
+type EllipseElement struct {
+	CX, CY, RX, RY float64
+	Style          Style
+	Attrs          []Attr
+}
+
+// Tag returns the element name
+func (e *EllipseElement) Tag() string { return "ellipse" }
+
+// Attributes returns the ellipse attributes
+func (e *EllipseElement) Attributes() []Attr {
+	attrs := []Attr{
+		{"cx", formatFloat(e.CX)},
+		{"cy", formatFloat(e.CY)},
+		{"rx", formatFloat(e.RX)},
+		{"ry", formatFloat(e.RY)},
+	}
+	attrs = append(attrs, styleAttrs(e.Style)...)
+	return append(attrs, e.Attrs...)
+}
+
+// Children returns no children
+func (e *EllipseElement) Children() []Element { return nil }
+
+// WriteTo writes the ellipse markup to w
+func (e *EllipseElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// LineElement represents an SVG <line> element
+type LineElement struct {
+	X1, Y1, X2, Y2 float64
+	Style          Style
+	Attrs          []Attr
+}
+
+// Tag returns the element name
+func (e *LineElement) Tag() string { return "line" }
+
+// Attributes returns the line attributes
+func (e *LineElement) Attributes() []Attr {
+	attrs := []Attr{
+		{"x1", formatFloat(e.X1)},
+		{"y1", formatFloat(e.Y1)},
+		{"x2", formatFloat(e.X2)},
+		{"y2", formatFloat(e.Y2)},
+	}
+	attrs = append(attrs, styleAttrs(e.Style)...)
+	return append(attrs, e.Attrs...)
+}
+
+// Children returns no children
+func (e *LineElement) Children() []Element { return nil }
+
+// WriteTo writes the line markup to w
+func (e *LineElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// PolygonElement represents an SVG <polygon> element
+type PolygonElement struct {
+	Points []Point
+	Style  Style
+	Attrs  []Attr
+}
+
+// Tag returns the element name
+func (e *PolygonElement) Tag() string { return "polygon" }
+
+// Attributes returns the polygon attributes
+func (e *PolygonElement) Attributes() []Attr {
+	attrs := []Attr{{"points", formatPoints(e.Points)}}
+	attrs = append(attrs, styleAttrs(e.Style)...)
+	return append(attrs, e.Attrs...)
+}
+
+// Children returns no children
+func (e *PolygonElement) Children() []Element { return nil }
+
+// WriteTo writes the polygon markup to w
+func (e *PolygonElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// PolylineElement represents an SVG <polyline> element
+type PolylineElement struct {
+	Points []Point
+	Style  Style
+	Attrs  []Attr
+}
+
+// Tag returns the element name
+func (e *PolylineElement) Tag() string { return "polyline" }
+
+// Attributes returns the polyline attributes
+func (e *PolylineElement) Attributes() []Attr {
+	attrs := []Attr{{"points", formatPoints(e.Points)}}
+	attrs = append(attrs, styleAttrs(e.Style)...)
+	return append(attrs, e.Attrs...)
+}
+
+// Children returns no children
+func (e *PolylineElement) Children() []Element { return nil }
+
+// WriteTo writes the polyline markup to w
+func (e *PolylineElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// PathElement represents an SVG <path> element
+type PathElement struct {
+	D     string
+	Style Style
+	Attrs []Attr
+}
+
+// Tag returns the element name
+func (e *PathElement) Tag() string { return "path" }
+
+// Attributes returns the path attributes
+func (e *PathElement) Attributes() []Attr {
+	attrs := []Attr{{"d", e.D}}
+	attrs = append(attrs, styleAttrs(e.Style)...)
+	return append(attrs, e.Attrs...)
+}
+
+// Children returns no children
+func (e *PathElement) Children() []Element { return nil }
+
+// WriteTo writes the path markup to w
+func (e *PathElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// TextElement represents an SVG <text> element
+// Content is written first, followed by any span elements
+type TextElement struct {
+	X, Y    float64
+	Content string
+	Spans   []Element
+	Style   Style
+	Attrs   []Attr
+}
+
+// Tag returns the element name
+func (e *TextElement) Tag() string { return "text" }
+
+// Attributes returns the text attributes
+func (e *TextElement) Attributes() []Attr {
+	attrs := []Attr{
+		{"x", formatFloat(e.X)},
+		{"y", formatFloat(e.Y)},
+	}
+	attrs = append(attrs, styleAttrs(e.Style)...)
+	return append(attrs, e.Attrs...)
+}
+
+// Children returns the text content and spans
+func (e *TextElement) Children() []Element {
+	var children []Element
+	if e.Content != "" {
+		children = append(children, CharData(e.Content))
+	}
+	return append(children, e.Spans...)
+}
+
+// WriteTo writes the text markup to w
+func (e *TextElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// TSpanElement represents an SVG <tspan> element
+type TSpanElement struct {
+	Content string
+	DX, DY  float64
+	Style   Style
+	Attrs   []Attr
+}
+
+// Tag returns the element name
+func (e *TSpanElement) Tag() string { return "tspan" }
+
+// Attributes returns the tspan attributes
+func (e *TSpanElement) Attributes() []Attr {
+	var attrs []Attr
+	if e.DX != 0 {
+		attrs = append(attrs, Attr{"dx", formatFloat(e.DX)})
+	}
+	if e.DY != 0 {
+		attrs = append(attrs, Attr{"dy", formatFloat(e.DY)})
+	}
+	attrs = append(attrs, styleAttrs(e.Style)...)
+	return append(attrs, e.Attrs...)
+}
+
+// Children returns the span text
+func (e *TSpanElement) Children() []Element {
+	if e.Content == "" {
+		return nil
+	}
+	return []Element{CharData(e.Content)}
+}
+
+// WriteTo writes the tspan markup to w
+func (e *TSpanElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// TextPathElement represents an SVG <textPath> element
+type TextPathElement struct {
+	Content     string
+	PathID      string
+	StartOffset string
+	Style       Style
+	Attrs       []Attr
+}
+
+// Tag returns the element name
+func (e *TextPathElement) Tag() string { return "textPath" }
+
+// Attributes returns the textPath attributes
+func (e *TextPathElement) Attributes() []Attr {
+	attrs := []Attr{{"href", "#" + e.PathID}}
+	if e.StartOffset != "" {
+		attrs = append(attrs, Attr{"startOffset", e.StartOffset})
+	}
+	attrs = append(attrs, styleAttrs(e.Style)...)
+	return append(attrs, e.Attrs...)
+}
+
+// Children returns the text content
+func (e *TextPathElement) Children() []Element {
+	if e.Content == "" {
+		return nil
+	}
+	return []Element{CharData(e.Content)}
+}
+
+// WriteTo writes the textPath markup to w
+func (e *TextPathElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
 // Rect renders an SVG rectangle
 func Rect(x, y, width, height float64, style Style) string {
-	attrs := formatStyle(style)
-	return fmt.Sprintf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f"%s/>`,
-		x, y, width, height, attrs)
+	return Serialize(&RectElement{X: x, Y: y, Width: width, Height: height, Style: style})
 }
 
 // RoundedRect renders an SVG rectangle with rounded corners
 func RoundedRect(x, y, width, height, rx, ry float64, style Style) string {
-	attrs := formatStyle(style)
 	if ry == 0 {
 		ry = rx // If ry not specified, use rx for both
 	}
-	return fmt.Sprintf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" rx="%.2f" ry="%.2f"%s/>`,
-		x, y, width, height, rx, ry, attrs)
+	return Serialize(&RectElement{X: x, Y: y, Width: width, Height: height, RX: rx, RY: ry, Style: style})
 }
 
 // Circle renders an SVG circle
 func Circle(cx, cy, r float64, style Style) string {
-	attrs := formatStyle(style)
-	return fmt.Sprintf(`<circle cx="%.2f" cy="%.2f" r="%.2f"%s/>`,
-		cx, cy, r, attrs)
+	return Serialize(&CircleElement{CX: cx, CY: cy, R: r, Style: style})
 }
 
 // Ellipse renders an SVG ellipse
 func Ellipse(cx, cy, rx, ry float64, style Style) string {
-	attrs := formatStyle(style)
-	return fmt.Sprintf(`<ellipse cx="%.2f" cy="%.2f" rx="%.2f" ry="%.2f"%s/>`,
-		cx, cy, rx, ry, attrs)
+	return Serialize(&EllipseElement{CX: cx, CY: cy, RX: rx, RY: ry, Style: style})
 }
 
 // Polygon renders an SVG polygon (closed shape from points)
@@ -133,17 +414,7 @@ func Polygon(points []Point, style Style) string {
 	if len(points) == 0 {
 		return ""
 	}
-
-	var pointsStr strings.Builder
-	for i, p := range points {
-		if i > 0 {
-			pointsStr.WriteString(" ")
-		}
-		fmt.Fprintf(&pointsStr, "%.2f,%.2f", p.X, p.Y)
-	}
-
-	attrs := formatStyle(style)
-	return fmt.Sprintf(`<polygon points="%s"%s/>`, pointsStr.String(), attrs)
+	return Serialize(&PolygonElement{Points: points, Style: style})
 }
 
 // Polyline renders an SVG polyline (open shape from points)
@@ -151,80 +422,43 @@ func Polyline(points []Point, style Style) string {
 	if len(points) == 0 {
 		return ""
 	}
-
-	var pointsStr strings.Builder
-	for i, p := range points {
-		if i > 0 {
-			pointsStr.WriteString(" ")
-		}
-		fmt.Fprintf(&pointsStr, "%.2f,%.2f", p.X, p.Y)
-	}
-
-	attrs := formatStyle(style)
-	return fmt.Sprintf(`<polyline points="%s"%s/>`, pointsStr.String(), attrs)
+	return Serialize(&PolylineElement{Points: points, Style: style})
 }
 
 // Line renders an SVG line
 func Line(x1, y1, x2, y2 float64, style Style) string {
-	attrs := formatStyle(style)
-	return fmt.Sprintf(`<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f"%s/>`,
-		x1, y1, x2, y2, attrs)
+	return Serialize(&LineElement{X1: x1, Y1: y1, X2: x2, Y2: y2, Style: style})
 }
 
 // Text renders an SVG text element
 func Text(content string, x, y float64, style Style) string {
-	attrs := formatStyle(style)
-	return fmt.Sprintf(`<text x="%.2f" y="%.2f"%s>%s</text>`,
-		x, y, attrs, escapeXML(content))
+	return Serialize(&TextElement{X: x, Y: y, Content: content, Style: style})
 }
 
 // TSpan renders an SVG tspan element (for use inside text elements)
 // TSpan allows styling different parts of text independently
 func TSpan(content string, style Style, dx, dy float64) string {
-	attrs := formatStyle(style)
-	posAttrs := ""
-	if dx != 0 {
-		posAttrs += fmt.Sprintf(` dx="%.2f"`, dx)
-	}
-	if dy != 0 {
-		posAttrs += fmt.Sprintf(` dy="%.2f"`, dy)
-	}
-	return fmt.Sprintf(`<tspan%s%s>%s</tspan>`, posAttrs, attrs, escapeXML(content))
+	return Serialize(&TSpanElement{Content: content, DX: dx, DY: dy, Style: style})
 }
 
 // TextWithSpans renders an SVG text element with multiple styled spans
 func TextWithSpans(x, y float64, style Style, spans []string) string {
-	attrs := formatStyle(style)
-	return fmt.Sprintf(`<text x="%.2f" y="%.2f"%s>%s</text>`,
-		x, y, attrs, strings.Join(spans, ""))
+	return Serialize(&TextElement{X: x, Y: y, Style: style, Spans: []Element{Raw(strings.Join(spans, ""))}})
 }
 
 // TextPath renders text along a path
 func TextPath(content string, pathID string, style Style, startOffset string) string {
-	attrs := formatStyle(style)
-	offsetAttr := ""
-	if startOffset != "" {
-		offsetAttr = fmt.Sprintf(` startOffset="%s"`, startOffset)
-	}
-	return fmt.Sprintf(`<textPath href="#%s"%s%s>%s</textPath>`,
-		pathID, offsetAttr, attrs, escapeXML(content))
+	return Serialize(&TextPathElement{Content: content, PathID: pathID, StartOffset: startOffset, Style: style})
 }
 
 // Path renders an SVG path
 func Path(d string, style Style) string {
-	attrs := formatStyle(style)
-	return fmt.Sprintf(`<path d="%s"%s/>`, d, attrs)
+	return Serialize(&PathElement{D: d, Style: style})
 }
 
 // Group wraps content in an SVG <g> element with optional transform
 func Group(content string, transform string, style Style) string {
-	var attrs string
-	if transform != "" {
-		attrs = fmt.Sprintf(` transform="%s"`, transform)
-	}
-	attrs += formatStyle(style)
-
-	return fmt.Sprintf(`<g%s>%s</g>`, attrs, content)
+	return Serialize(&GroupElement{Transform: transform, Style: style, Elements: []Element{Raw(content)}})
 }
 
 // GroupWithClipPath wraps content in an SVG <g> element with clipPath
@@ -235,66 +469,104 @@ func GroupWithClipPath(content string, clipPathID string, style Style) string {
 
 // formatStyle converts a Style struct to SVG attribute string
 func formatStyle(s Style) string {
-	var attrs []string
+	return formatAttrs(styleAttrs(s))
+}
+
+// formatAttrs joins attributes into a string with a leading space
+func formatAttrs(attrs []Attr) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, attr := range attrs {
+		b.WriteString(" ")
+		b.WriteString(attr.Name)
+		b.WriteString(`="`)
+		b.WriteString(escapeXML(attr.Value))
+		b.WriteString(`"`)
+	}
+	return b.String()
+}
+
+// styleAttrs converts a Style struct to SVG attributes
+func styleAttrs(s Style) []Attr {
+	var attrs []Attr
 
 	if s.Fill != "" {
-		attrs = append(attrs, fmt.Sprintf(`fill="%s"`, s.Fill))
+		attrs = append(attrs, Attr{"fill", s.Fill})
 	}
 	if s.Stroke != "" {
-		attrs = append(attrs, fmt.Sprintf(`stroke="%s"`, s.Stroke))
+		attrs = append(attrs, Attr{"stroke", s.Stroke})
 	}
 	if s.StrokeWidth > 0 {
-		attrs = append(attrs, fmt.Sprintf(`stroke-width="%.2f"`, s.StrokeWidth))
+		attrs = append(attrs, Attr{"stroke-width", formatFloat(s.StrokeWidth)})
 	}
 	if s.StrokeDashArray != "" {
-		attrs = append(attrs, fmt.Sprintf(`stroke-dasharray="%s"`, s.StrokeDashArray))
+		attrs = append(attrs, Attr{"stroke-dasharray", s.StrokeDashArray})
 	}
 	if s.StrokeLinecap != "" {
-		attrs = append(attrs, fmt.Sprintf(`stroke-linecap="%s"`, string(s.StrokeLinecap)))
+		attrs = append(attrs, Attr{"stroke-linecap", string(s.StrokeLinecap)})
 	}
 	if s.StrokeLinejoin != "" {
-		attrs = append(attrs, fmt.Sprintf(`stroke-linejoin="%s"`, string(s.StrokeLinejoin)))
+		attrs = append(attrs, Attr{"stroke-linejoin", string(s.StrokeLinejoin)})
 	}
 	if s.Opacity > 0 && s.Opacity < 1 {
-		attrs = append(attrs, fmt.Sprintf(`opacity="%.2f"`, s.Opacity))
+		attrs = append(attrs, Attr{"opacity", formatFloat(s.Opacity)})
 	}
 	if s.FillOpacity > 0 && s.FillOpacity < 1 {
-		attrs = append(attrs, fmt.Sprintf(`fill-opacity="%.2f"`, s.FillOpacity))
+		attrs = append(attrs, Attr{"fill-opacity", formatFloat(s.FillOpacity)})
 	}
 	if s.StrokeOpacity > 0 && s.StrokeOpacity < 1 {
-		attrs = append(attrs, fmt.Sprintf(`stroke-opacity="%.2f"`, s.StrokeOpacity))
+		attrs = append(attrs, Attr{"stroke-opacity", formatFloat(s.StrokeOpacity)})
 	}
 	if s.Class != "" {
-		attrs = append(attrs, fmt.Sprintf(`class="%s"`, s.Class))
+		attrs = append(attrs, Attr{"class", s.Class})
 	}
 	if s.ClipPath != "" {
-		attrs = append(attrs, fmt.Sprintf(`clip-path="%s"`, s.ClipPath))
+		attrs = append(attrs, Attr{"clip-path", s.ClipPath})
 	}
 	if s.TextAnchor != "" {
-		attrs = append(attrs, fmt.Sprintf(`text-anchor="%s"`, string(s.TextAnchor)))
+		attrs = append(attrs, Attr{"text-anchor", string(s.TextAnchor)})
 	}
 	if s.DominantBaseline != "" {
-		attrs = append(attrs, fmt.Sprintf(`dominant-baseline="%s"`, string(s.DominantBaseline)))
+		attrs = append(attrs, Attr{"dominant-baseline", string(s.DominantBaseline)})
 	}
 	if s.FontFamily != "" {
-		attrs = append(attrs, fmt.Sprintf(`font-family="%s"`, s.FontFamily))
+		attrs = append(attrs, Attr{"font-family", s.FontFamily})
 	}
 	if s.FontSize.Value != 0 {
 		// Format as "valueunit" (e.g., "16px", "1.5em", "2rem")
-		attrs = append(attrs, fmt.Sprintf(`font-size="%s"`, s.FontSize.String()))
+		attrs = append(attrs, Attr{"font-size", s.FontSize.String()})
 	}
 	if s.FontWeight != "" {
-		attrs = append(attrs, fmt.Sprintf(`font-weight="%s"`, string(s.FontWeight)))
+		attrs = append(attrs, Attr{"font-weight", string(s.FontWeight)})
 	}
 	if s.FontStyle != "" {
-		attrs = append(attrs, fmt.Sprintf(`font-style="%s"`, string(s.FontStyle)))
+		attrs = append(attrs, Attr{"font-style", string(s.FontStyle)})
 	}
-
-	if len(attrs) == 0 {
-		return ""
+	if s.Cursor != "" {
+		attrs = append(attrs, Attr{"cursor", s.Cursor})
 	}
+	if s.PointerEvents != "" {
+		attrs = append(attrs, Attr{"pointer-events", s.PointerEvents})
+	}
+
+	return attrs
+}
 
-	return " " + strings.Join(attrs, " ")
+// formatPoints formats points for a polygon or polyline points attribute
+func formatPoints(points []Point) string {
+	var b strings.Builder
+	for i, p := range points {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(formatFloat(p.X))
+		b.WriteString(",")
+		b.WriteString(formatFloat(p.Y))
+	}
+	return b.String()
 }
 
 // escapeXML escapes special XML characters in text content
@@ -50,21 +50,25 @@ func main() {
 	opts.Width = 400
 	opts.Height = 200
 	opts.BackgroundColor = "#f8f9fa"
-	opts.StyleFunc = func(node interface{}, depth int) svg.Style {
-		if depth == 0 {
-			// Root node - transparent
-			return svg.Style{
-				Fill:   "none",
-				Stroke: "#dee2e6",
+	opts.NodeRenderers = []svg.NodeRenderer{svg.NodeHooks{
+		BeforeFunc: func(node *layout.Node, ctx *svg.RenderContext) []svg.Element {
+			if ctx.Depth == 0 {
+				// Root node - transparent
+				ctx.Style = svg.Style{
+					Fill:   "none",
+					Stroke: "#dee2e6",
+				}
+				return nil
 			}
-		}
-		// Children - colored boxes
-		return svg.Style{
-			Fill:        "#6366f1",
-			Stroke:      "#4f46e5",
-			StrokeWidth: 2,
-		}
-	}
+			// Children - colored boxes
+			ctx.Style = svg.Style{
+				Fill:        "#6366f1",
+				Stroke:      "#4f46e5",
+				StrokeWidth: 2,
+			}
+			return nil
+		},
+	}}
 
 	output := svg.RenderToSVG(root, opts)
 
@@ -2,17 +2,20 @@ package svg
 
 import (
 	"bytes"
-	"encoding/xml"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
 	"image/jpeg"
 	"image/png"
+	"io"
 	"math"
 	"strconv"
 	"strings"
 
+	"github.com/SCKelemen/units"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
 	"golang.org/x/image/vector"
 )
 
@@ -26,6 +29,8 @@ const (
 	FormatPNG ExportFormat = "png"
 	// FormatJPEG exports as JPEG
 	FormatJPEG ExportFormat = "jpeg"
+	// FormatPDF exports as PDF, with each page rasterized
+	FormatPDF ExportFormat = "pdf"
 )
 
 // ExportOptions configures export settings
@@ -35,6 +40,15 @@ type ExportOptions struct {
 	Height  int // For raster formats, 0 = use SVG dimensions
 	Quality int // For JPEG, 0-100 (default 90)
 	DPI     int // Dots per inch (default 96)
+
+	// ColorScheme selects which colors var() references resolve to when
+	// rasterizing, following @media (prefers-color-scheme) blocks in <style>
+	// (default light)
+	ColorScheme ColorScheme
+
+	// TextMeasurer positions text when rasterizing (default DefaultTextMeasurer)
+	// Text is drawn when the measurer also provides font faces, as FontMeasurer does
+	TextMeasurer TextMeasurer
 }
 
 // DefaultExportOptions returns sensible defaults
@@ -52,12 +66,16 @@ func Export(svgData string, opts ExportOptions) ([]byte, error) {
 	if opts.Format == FormatSVG {
 		return []byte(svgData), nil
 	}
+	if opts.Format == FormatPDF {
+		return ExportPDF([]string{svgData}, opts)
+	}
 
 	// For raster formats, parse and rasterize
 	return rasterize(svgData, opts)
 }
 
 // svgElement represents a parsed SVG element
+// Text nodes are kept in document order as children with an empty Tag
 type svgElement struct {
 	Tag        string
 	Attributes map[string]string
@@ -65,69 +83,131 @@ type svgElement struct {
 	Text       string
 }
 
-// parseSVG performs basic SVG parsing for our own generated SVG
+// parseSVG parses SVG data into the simplified tree used by the rasterizer
 func parseSVG(svgData string) (*svgElement, error) {
-	decoder := xml.NewDecoder(strings.NewReader(svgData))
-
-	var root *svgElement
-	var stack []*svgElement
+	doc, err := ParseString(svgData)
+	if err != nil {
+		return nil, err
+	}
 
-	for {
-		token, err := decoder.Token()
-		if err != nil {
-			break
-		}
+	root := toSVGElement(doc)
+	return &root, nil
+}
 
-		switch t := token.(type) {
-		case xml.StartElement:
-			elem := &svgElement{
-				Tag:        t.Name.Local,
-				Attributes: make(map[string]string),
-			}
+// toSVGElement converts an element tree to the rasterizer's representation
+// Attributes are keyed by local name, and Text joins the element's own text
+// nodes with spaces
+func toSVGElement(e Element) svgElement {
+	elem := svgElement{
+		Tag:        localName(e.Tag()),
+		Attributes: make(map[string]string),
+	}
 
-			for _, attr := range t.Attr {
-				elem.Attributes[attr.Name.Local] = attr.Value
-			}
+	for _, attr := range e.Attributes() {
+		elem.Attributes[localName(attr.Name)] = attr.Value
+	}
 
-			if len(stack) > 0 {
-				parent := stack[len(stack)-1]
-				parent.Children = append(parent.Children, *elem)
-			} else {
-				root = elem
+	for _, child := range e.Children() {
+		switch c := child.(type) {
+		case CharData:
+			if text := strings.TrimSpace(string(c)); text != "" {
+				if elem.Text != "" {
+					elem.Text += " "
+				}
+				elem.Text += text
+				elem.Children = append(elem.Children, svgElement{Text: text})
 			}
+		case Comment, Raw:
+			// Not rendered
+		default:
+			elem.Children = append(elem.Children, toSVGElement(child))
+		}
+	}
 
-			stack = append(stack, elem)
+	return elem
+}
 
-		case xml.EndElement:
-			if len(stack) > 0 {
-				stack = stack[:len(stack)-1]
-			}
+// resolveCustomProperties replaces var() references in attribute values
+// using the custom properties defined by the document's <style> elements
+func resolveCustomProperties(root *svgElement, scheme ColorScheme) {
+	props := make(map[string]string)
+	collectStyleProperties(root, scheme, props)
+	if len(props) == 0 {
+		return
+	}
+	resolveElementVars(root, props)
+}
 
-		case xml.CharData:
-			if len(stack) > 0 {
-				text := strings.TrimSpace(string(t))
-				if text != "" {
-					stack[len(stack)-1].Text = text
-				}
+func collectStyleProperties(elem *svgElement, scheme ColorScheme, props map[string]string) {
+	if elem.Tag == "style" {
+		// Invalid CSS is ignored, as a browser would
+		if ss, err := ParseStyleSheet(elem.Text); err == nil {
+			for name, value := range ss.CustomProperties(scheme) {
+				props[name] = value
 			}
 		}
 	}
+	for i := range elem.Children {
+		collectStyleProperties(&elem.Children[i], scheme, props)
+	}
+}
 
-	if root == nil {
-		return nil, fmt.Errorf("no SVG root element found")
+func resolveElementVars(elem *svgElement, props map[string]string) {
+	for name, value := range elem.Attributes {
+		if strings.Contains(value, "var(") {
+			elem.Attributes[name] = resolveVars(value, props)
+		}
 	}
+	for i := range elem.Children {
+		resolveElementVars(&elem.Children[i], props)
+	}
+}
 
-	return root, nil
+// localName strips the namespace prefix from a qualified name
+func localName(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
 }
 
 // rasterize converts SVG to a raster image
 func rasterize(svgData string, opts ExportOptions) ([]byte, error) {
+	img, err := rasterizeImage(svgData, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encode to target format
+	var buf bytes.Buffer
+	switch opts.Format {
+	case FormatPNG:
+		encoder := png.Encoder{CompressionLevel: png.DefaultCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("failed to encode PNG: %w", err)
+		}
+	case FormatJPEG:
+		if err := encodeJPEG(&buf, img, opts.Quality); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", opts.Format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// rasterizeImage draws SVG onto a new image with a white background
+func rasterizeImage(svgData string, opts ExportOptions) (*image.RGBA, error) {
 	// Parse SVG
 	root, err := parseSVG(svgData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse SVG: %w", err)
 	}
 
+	// Resolve custom properties for the chosen color scheme
+	resolveCustomProperties(root, opts.ColorScheme)
+
 	// Get dimensions
 	width, height, err := getSVGDimensions(root, opts)
 	if err != nil {
@@ -143,39 +223,35 @@ func rasterize(svgData string, opts ExportOptions) ([]byte, error) {
 	// Create rasterizer
 	rasterizer := vector.NewRasterizer(width, height)
 
+	measurer := opts.TextMeasurer
+	if measurer == nil {
+		measurer = DefaultTextMeasurer()
+	}
+
 	// Render SVG elements
-	if err := renderElement(root, img, rasterizer, width, height); err != nil {
+	if err := renderElement(root, img, rasterizer, measurer, width, height); err != nil {
 		return nil, fmt.Errorf("failed to render SVG: %w", err)
 	}
 
-	// Encode to target format
-	var buf bytes.Buffer
-	switch opts.Format {
-	case FormatPNG:
-		encoder := png.Encoder{CompressionLevel: png.DefaultCompression}
-		if err := encoder.Encode(&buf, img); err != nil {
-			return nil, fmt.Errorf("failed to encode PNG: %w", err)
-		}
-	case FormatJPEG:
-		quality := opts.Quality
-		if quality == 0 {
-			quality = 90
-		}
-		if quality < 1 {
-			quality = 1
-		}
-		if quality > 100 {
-			quality = 100
-		}
-		jpegOpts := &jpeg.Options{Quality: quality}
-		if err := jpeg.Encode(&buf, img, jpegOpts); err != nil {
-			return nil, fmt.Errorf("failed to encode JPEG: %w", err)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported format: %s", opts.Format)
-	}
+	return img, nil
+}
 
-	return buf.Bytes(), nil
+// encodeJPEG encodes an image as JPEG at the given quality (default 90)
+func encodeJPEG(w io.Writer, img image.Image, quality int) error {
+	if quality == 0 {
+		quality = 90
+	}
+	if quality < 1 {
+		quality = 1
+	}
+	if quality > 100 {
+		quality = 100
+	}
+	jpegOpts := &jpeg.Options{Quality: quality}
+	if err := jpeg.Encode(w, img, jpegOpts); err != nil {
+		return fmt.Errorf("failed to encode JPEG: %w", err)
+	}
+	return nil
 }
 
 // getSVGDimensions extracts width and height from SVG
@@ -236,12 +312,12 @@ func parseLength(s string) int {
 }
 
 // renderElement renders an SVG element to the image
-func renderElement(elem *svgElement, img *image.RGBA, rasterizer *vector.Rasterizer, width, height int) error {
+func renderElement(elem *svgElement, img *image.RGBA, rasterizer *vector.Rasterizer, measurer TextMeasurer, width, height int) error {
 	switch elem.Tag {
 	case "svg":
 		// Render children
 		for _, child := range elem.Children {
-			if err := renderElement(&child, img, rasterizer, width, height); err != nil {
+			if err := renderElement(&child, img, rasterizer, measurer, width, height); err != nil {
 				return err
 			}
 		}
@@ -258,15 +334,13 @@ func renderElement(elem *svgElement, img *image.RGBA, rasterizer *vector.Rasteri
 	case "g":
 		// Group - render children
 		for _, child := range elem.Children {
-			if err := renderElement(&child, img, rasterizer, width, height); err != nil {
+			if err := renderElement(&child, img, rasterizer, measurer, width, height); err != nil {
 				return err
 			}
 		}
 
 	case "text":
-		// Text rendering is complex and requires font support
-		// For now, we'll skip text elements
-		// TODO: Implement text rendering using golang.org/x/image/font
+		return renderText(elem, img, measurer)
 
 	case "path":
 		// Path rendering is complex
@@ -275,7 +349,7 @@ func renderElement(elem *svgElement, img *image.RGBA, rasterizer *vector.Rasteri
 	default:
 		// Unknown or unsupported element, continue rendering children
 		for _, child := range elem.Children {
-			if err := renderElement(&child, img, rasterizer, width, height); err != nil {
+			if err := renderElement(&child, img, rasterizer, measurer, width, height); err != nil {
 				return err
 			}
 		}
@@ -346,6 +420,126 @@ func renderLine(elem *svgElement, img *image.RGBA, rasterizer *vector.Rasterizer
 	return nil
 }
 
+// fontFaceProvider is implemented by text measurers that can draw glyphs
+type fontFaceProvider interface {
+	Face(style Style) (font.Face, error)
+}
+
+// renderText renders a text element and its tspans
+func renderText(elem *svgElement, img *image.RGBA, measurer TextMeasurer) error {
+	style := textStyle(elem.Attributes, Style{Fill: "black"})
+	x := parseCoordinate(elem.Attributes["x"])
+	y := parseCoordinate(elem.Attributes["y"])
+	drawTextContent(img, measurer, elem, x, y, style)
+	return nil
+}
+
+// drawTextContent draws the text nodes and tspans of an element in document
+// order, starting at the given position, and returns the position after them
+func drawTextContent(img *image.RGBA, measurer TextMeasurer, elem *svgElement, x, y float64, style Style) (float64, float64) {
+	for i := range elem.Children {
+		child := &elem.Children[i]
+		switch child.Tag {
+		case "":
+			x = drawTextRun(img, measurer, child.Text, x, y, style)
+
+		case "tspan":
+			if v, ok := child.Attributes["x"]; ok {
+				x = parseCoordinate(v)
+			}
+			if v, ok := child.Attributes["y"]; ok {
+				y = parseCoordinate(v)
+			}
+			x += parseCoordinate(child.Attributes["dx"])
+			y += parseCoordinate(child.Attributes["dy"])
+			x, y = drawTextContent(img, measurer, child, x, y, textStyle(child.Attributes, style))
+		}
+	}
+	return x, y
+}
+
+// drawTextRun draws a run of text at the baseline position and returns the
+// x position after it
+func drawTextRun(img *image.RGBA, measurer TextMeasurer, text string, x, y float64, style Style) float64 {
+	if text == "" {
+		return x
+	}
+
+	width := measurer.Measure(text, style).Width
+	switch style.TextAnchor {
+	case TextAnchorMiddle:
+		x -= width / 2
+	case TextAnchorEnd:
+		x -= width
+	}
+
+	if faces, ok := measurer.(fontFaceProvider); ok && style.Fill != "none" {
+		if face, err := faces.Face(style); err == nil {
+			defer face.Close()
+			d := font.Drawer{
+				Dst:  img,
+				Src:  image.NewUniform(parseColor(style.Fill)),
+				Face: face,
+				Dot:  fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(y * 64)},
+			}
+			d.DrawString(text)
+		}
+	}
+
+	return x + width
+}
+
+// textStyle reads font and fill attributes over an inherited style
+func textStyle(attrs map[string]string, inherited Style) Style {
+	style := inherited
+	if v, ok := attrs["fill"]; ok {
+		style.Fill = v
+	}
+	if v, ok := attrs["font-family"]; ok {
+		style.FontFamily = v
+	}
+	if v, ok := attrs["font-size"]; ok {
+		style.FontSize = parseFontSize(v)
+	}
+	if v, ok := attrs["font-weight"]; ok {
+		style.FontWeight = FontWeight(v)
+	}
+	if v, ok := attrs["font-style"]; ok {
+		style.FontStyle = FontStyle(v)
+	}
+	if v, ok := attrs["text-anchor"]; ok {
+		style.TextAnchor = TextAnchor(v)
+	}
+	return style
+}
+
+// parseFontSize parses a CSS font size such as "12", "12px" or "1.5em"
+func parseFontSize(s string) units.Length {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '-' && r != '+'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return units.Length{}
+	}
+	unit := units.LengthUnit(strings.ToLower(s[i:]))
+	if unit == "" {
+		unit = units.PX
+	}
+	return units.Length{Value: v, Unit: unit}
+}
+
+// parseCoordinate parses a coordinate, ignoring a px suffix
+func parseCoordinate(s string) float64 {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "px")
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
 // parseColor parses a color string (hex or named)
 func parseColor(s string) color.Color {
 	s = strings.TrimSpace(s)
@@ -429,6 +623,8 @@ func GetMimeType(format ExportFormat) string {
 		return "image/png"
 	case FormatJPEG:
 		return "image/jpeg"
+	case FormatPDF:
+		return "application/pdf"
 	default:
 		return "application/octet-stream"
 	}
@@ -443,6 +639,8 @@ func GetFileExtension(format ExportFormat) string {
 		return ".png"
 	case FormatJPEG:
 		return ".jpg"
+	case FormatPDF:
+		return ".pdf"
 	default:
 		return ".bin"
 	}
@@ -458,6 +656,8 @@ func ParseFormat(s string) (ExportFormat, error) {
 		return FormatPNG, nil
 	case "jpeg", "jpg":
 		return FormatJPEG, nil
+	case "pdf":
+		return FormatPDF, nil
 	default:
 		return "", fmt.Errorf("unknown format: %s", s)
 	}
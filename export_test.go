@@ -115,6 +115,7 @@ func TestParseFormat(t *testing.T) {
 		{"jpeg", FormatJPEG, false},
 		{"jpg", FormatJPEG, false},
 		{"JPEG", FormatJPEG, false},
+		{"pdf", FormatPDF, false},
 		{"unknown", "", true},
 	}
 
@@ -144,6 +145,7 @@ func TestGetMimeType(t *testing.T) {
 		{FormatSVG, "image/svg+xml"},
 		{FormatPNG, "image/png"},
 		{FormatJPEG, "image/jpeg"},
+		{FormatPDF, "application/pdf"},
 	}
 
 	for _, tt := range tests {
@@ -162,6 +164,7 @@ func TestGetFileExtension(t *testing.T) {
 		{FormatSVG, ".svg"},
 		{FormatPNG, ".png"},
 		{FormatJPEG, ".jpg"},
+		{FormatPDF, ".pdf"},
 	}
 
 	for _, tt := range tests {
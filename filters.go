@@ -0,0 +1,61 @@
+package svg
+
+import (
+	"io"
+)
+
+// FilterElement represents an SVG <filter> element
+type FilterElement struct {
+	ID string
+
+	// Filter region (optional); SVG defaults to -10%/-10%/120%/120%
+	X, Y, Width, Height string
+
+	// Units selects the coordinate system of the region (filterUnits)
+	Units GradientUnits
+
+	// Primitives are the filter primitives, such as GaussianBlur
+	Primitives []Element
+}
+
+// Tag returns the element name
+func (e *FilterElement) Tag() string { return "filter" }
+
+// Attributes returns the filter attributes
+func (e *FilterElement) Attributes() []Attr {
+	attrs := []Attr{{"id", e.ID}}
+	attrs = appendNonEmpty(attrs, "x", e.X)
+	attrs = appendNonEmpty(attrs, "y", e.Y)
+	attrs = appendNonEmpty(attrs, "width", e.Width)
+	attrs = appendNonEmpty(attrs, "height", e.Height)
+	attrs = appendNonEmpty(attrs, "filterUnits", string(e.Units))
+	return attrs
+}
+
+// Children returns the filter primitives
+func (e *FilterElement) Children() []Element { return e.Primitives }
+
+// WriteTo writes the filter markup to w
+func (e *FilterElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// GaussianBlur returns an <feGaussianBlur> primitive blurring the source graphic
+func GaussianBlur(stdDeviation float64) Element {
+	return &GenericElement{
+		Name:  "feGaussianBlur",
+		Attrs: []Attr{{"in", "SourceGraphic"}, {"stdDeviation", formatFloat(stdDeviation)}},
+	}
+}
+
+// BlurFilter creates a filter that blurs its element
+// The region is given in user space and should cover the blurred result
+func BlurFilter(id string, region Box, stdDeviation float64) *FilterElement {
+	return &FilterElement{
+		ID:         id,
+		X:          formatFloat(region.X),
+		Y:          formatFloat(region.Y),
+		Width:      formatFloat(region.Width),
+		Height:     formatFloat(region.Height),
+		Units:      GradientUnitsUserSpaceOnUse,
+		Primitives: []Element{GaussianBlur(stdDeviation)},
+	}
+}
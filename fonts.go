@@ -0,0 +1,347 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// EmbedFont adds an @font-face rule that embeds a font file as a base64 data URL
+//
+// TrueType, OpenType, WOFF and WOFF2 files are recognized by their signature.
+// Extra descriptors such as font-weight or font-style may be given.
+func (ss *StyleSheet) EmbedFont(family string, font []byte, descriptors ...Declaration) *StyleSheet {
+	mime, format := fontFormat(font)
+	src := fmt.Sprintf("url(data:%s;base64,%s) format(%s)", mime, base64.StdEncoding.EncodeToString(font), quoteCSS(format))
+
+	decls := []Declaration{
+		Decl("font-family", quoteCSS(family)),
+		Decl("src", src),
+	}
+	return ss.AddFontFace(append(decls, descriptors...)...)
+}
+
+// EmbedFontSubset embeds a TrueType font subset to the glyphs needed for text
+//
+// Use TextContent to collect the text of a document. Glyphs that are not
+// needed keep their IDs but lose their outlines, so metrics and kerning
+// stay valid while the payload shrinks.
+func (ss *StyleSheet) EmbedFontSubset(family string, font []byte, text string, descriptors ...Declaration) (*StyleSheet, error) {
+	subset, err := SubsetFont(font, text)
+	if err != nil {
+		return nil, err
+	}
+	return ss.EmbedFont(family, subset, descriptors...), nil
+}
+
+// fontFormat returns the MIME type and CSS format name of a font file
+func fontFormat(font []byte) (string, string) {
+	if len(font) >= 4 {
+		switch string(font[:4]) {
+		case "OTTO":
+			return "font/otf", "opentype"
+		case "wOFF":
+			return "font/woff", "woff"
+		case "wOF2":
+			return "font/woff2", "woff2"
+		}
+	}
+	return "font/ttf", "truetype"
+}
+
+// TextContent returns the text of every <text>, <tspan> and <textPath>
+// element in the given trees, e.g. to decide which glyphs to embed
+func TextContent(elements ...Element) string {
+	var b strings.Builder
+	for _, e := range elements {
+		collectText(e, false, &b)
+	}
+	return b.String()
+}
+
+func collectText(e Element, inText bool, b *strings.Builder) {
+	switch n := e.(type) {
+	case CharData:
+		if inText {
+			b.WriteString(string(n))
+		}
+		return
+	case Comment:
+		return
+	case Raw:
+		// Markup from string helpers is parsed so its text is found too
+		fragment, err := parseFragment(string(n))
+		if err != nil {
+			return
+		}
+		for _, child := range fragment {
+			collectText(child, inText, b)
+		}
+		return
+	}
+
+	switch localName(e.Tag()) {
+	case "text", "tspan", "textPath":
+		inText = true
+	}
+	for _, child := range e.Children() {
+		collectText(child, inText, b)
+	}
+}
+
+// SubsetFont returns a copy of a TrueType font with outlines only for the
+// glyphs needed to render text
+//
+// Glyph IDs are preserved: unused glyphs are emptied rather than removed,
+// so cmap, metrics and positioning tables need no rewriting. Composite
+// glyphs keep their components. Substitution tables (GSUB, morx) are
+// dropped, as ligatures and alternates would map text to emptied glyphs.
+// Fonts with CFF outlines are not supported.
+func SubsetFont(font []byte, text string) ([]byte, error) {
+	parsed, err := sfnt.Parse(font)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+
+	tables, err := readFontTables(font)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"head", "maxp", "loca", "glyf"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("font has no %s table; only TrueType outlines can be subset", tag)
+		}
+	}
+
+	head, maxp := tables["head"], tables["maxp"]
+	if len(head) < 54 || len(maxp) < 6 {
+		return nil, fmt.Errorf("font has a truncated head or maxp table")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	longLoca := binary.BigEndian.Uint16(head[50:]) != 0
+
+	glyphs, err := splitGlyphs(tables["loca"], tables["glyf"], numGlyphs, longLoca)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep .notdef plus the glyph of every character in the text
+	keep := map[int]bool{0: true}
+	var buf sfnt.Buffer
+	for _, r := range text {
+		if gid, err := parsed.GlyphIndex(&buf, r); err == nil && gid != 0 {
+			keep[int(gid)] = true
+		}
+	}
+
+	// Composite glyphs need their components
+	pending := make([]int, 0, len(keep))
+	for gid := range keep {
+		pending = append(pending, gid)
+	}
+	for len(pending) > 0 {
+		gid := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if gid >= len(glyphs) {
+			continue
+		}
+		for _, component := range glyphComponents(glyphs[gid]) {
+			if !keep[component] {
+				keep[component] = true
+				pending = append(pending, component)
+			}
+		}
+	}
+
+	// Rebuild glyf and a long-format loca
+	var glyf bytes.Buffer
+	loca := make([]byte, 4*(numGlyphs+1))
+	for gid, data := range glyphs {
+		binary.BigEndian.PutUint32(loca[4*gid:], uint32(glyf.Len()))
+		if keep[gid] {
+			glyf.Write(data)
+			for glyf.Len()%4 != 0 {
+				glyf.WriteByte(0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*numGlyphs:], uint32(glyf.Len()))
+
+	newHead := append([]byte(nil), head...)
+	binary.BigEndian.PutUint32(newHead[8:], 0)  // checkSumAdjustment, set when writing
+	binary.BigEndian.PutUint16(newHead[50:], 1) // indexToLocFormat: long
+
+	tables["glyf"] = glyf.Bytes()
+	tables["loca"] = loca
+	tables["head"] = newHead
+	delete(tables, "DSIG") // The signature no longer matches
+
+	// Substitutions lead to glyphs outside the closure of the text
+	for _, tag := range []string{"GSUB", "morx", "mort"} {
+		delete(tables, tag)
+	}
+
+	return writeFontTables(binary.BigEndian.Uint32(font), tables), nil
+}
+
+// readFontTables reads the table directory of an sfnt font
+func readFontTables(font []byte) (map[string][]byte, error) {
+	if len(font) < 12 {
+		return nil, fmt.Errorf("font is too short")
+	}
+	numTables := int(binary.BigEndian.Uint16(font[4:]))
+	if len(font) < 12+16*numTables {
+		return nil, fmt.Errorf("font has a truncated table directory")
+	}
+
+	tables := make(map[string][]byte, numTables)
+	for i := 0; i < numTables; i++ {
+		record := font[12+16*i:]
+		tag := string(record[:4])
+		offset := int(binary.BigEndian.Uint32(record[8:]))
+		length := int(binary.BigEndian.Uint32(record[12:]))
+		if offset < 0 || length < 0 || offset+length > len(font) {
+			return nil, fmt.Errorf("font table %s is out of bounds", tag)
+		}
+		tables[tag] = font[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// writeFontTables writes an sfnt font with the given tables and fixes up
+// the table checksums and head.checkSumAdjustment
+func writeFontTables(version uint32, tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+
+	header := make([]byte, 12+16*numTables)
+	binary.BigEndian.PutUint32(header[0:], version)
+	binary.BigEndian.PutUint16(header[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(16*numTables-searchRange))
+
+	var body bytes.Buffer
+	headOffset := -1
+	for i, tag := range tags {
+		data := tables[tag]
+		offset := len(header) + body.Len()
+		if tag == "head" {
+			headOffset = offset
+		}
+
+		record := header[12+16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], fontChecksum(data))
+		binary.BigEndian.PutUint32(record[8:], uint32(offset))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(data)))
+
+		body.Write(data)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+
+	out := append(header, body.Bytes()...)
+	if headOffset >= 0 {
+		binary.BigEndian.PutUint32(out[headOffset+8:], 0xB1B0AFBA-fontChecksum(out))
+	}
+	return out
+}
+
+// fontChecksum computes an sfnt table checksum
+func fontChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// splitGlyphs returns the glyf data of each glyph using the loca offsets
+func splitGlyphs(loca, glyf []byte, numGlyphs int, long bool) ([][]byte, error) {
+	offset := func(i int) int {
+		if long {
+			return int(binary.BigEndian.Uint32(loca[4*i:]))
+		}
+		return 2 * int(binary.BigEndian.Uint16(loca[2*i:]))
+	}
+
+	size := 2
+	if long {
+		size = 4
+	}
+	if len(loca) < size*(numGlyphs+1) {
+		return nil, fmt.Errorf("font has a truncated loca table")
+	}
+
+	glyphs := make([][]byte, numGlyphs)
+	for i := range glyphs {
+		start, end := offset(i), offset(i+1)
+		if start > end || end > len(glyf) {
+			return nil, fmt.Errorf("glyph %d is out of bounds", i)
+		}
+		glyphs[i] = glyf[start:end]
+	}
+	return glyphs, nil
+}
+
+// Composite glyph flags
+const (
+	glyphArgsAreWords  = 0x0001
+	glyphHasScale      = 0x0008
+	glyphMoreComponent = 0x0020
+	glyphHasXYScale    = 0x0040
+	glyphHas2x2        = 0x0080
+)
+
+// glyphComponents returns the glyph IDs referenced by a composite glyph
+func glyphComponents(data []byte) []int {
+	if len(data) < 10 || int16(binary.BigEndian.Uint16(data)) >= 0 {
+		return nil
+	}
+
+	var components []int
+	pos := 10
+	for pos+4 <= len(data) {
+		flags := binary.BigEndian.Uint16(data[pos:])
+		components = append(components, int(binary.BigEndian.Uint16(data[pos+2:])))
+		pos += 4
+
+		if flags&glyphArgsAreWords != 0 {
+			pos += 4
+		} else {
+			pos += 2
+		}
+		switch {
+		case flags&glyphHasScale != 0:
+			pos += 2
+		case flags&glyphHasXYScale != 0:
+			pos += 4
+		case flags&glyphHas2x2 != 0:
+			pos += 8
+		}
+
+		if flags&glyphMoreComponent == 0 {
+			break
+		}
+	}
+	return components
+}
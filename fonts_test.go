@@ -0,0 +1,118 @@
+package svg
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestEmbedFont(t *testing.T) {
+	ss := (&StyleSheet{}).EmbedFont("Go", goregular.TTF, Decl("font-weight", "400"))
+	css := ss.CSS()
+
+	tests := []string{
+		"@font-face {",
+		`font-family: "Go";`,
+		"src: url(data:font/ttf;base64," + base64.StdEncoding.EncodeToString(goregular.TTF[:30]),
+		`format("truetype")`,
+		"font-weight: 400;",
+	}
+
+	for _, want := range tests {
+		if !strings.Contains(css, want) {
+			t.Errorf("expected %q in CSS", want)
+		}
+	}
+}
+
+func TestEmbedFontFamilyEscaping(t *testing.T) {
+	css := (&StyleSheet{}).EmbedFont("My \"Font\" \\ é\n", goregular.TTF).CSS()
+	if want := `font-family: "My \"Font\" \\ é\a ";`; !strings.Contains(css, want) {
+		t.Errorf("expected %q in CSS", want)
+	}
+}
+
+func TestTextContent(t *testing.T) {
+	doc := NewDocument(100, 100)
+	doc.Add(
+		&TextElement{Content: "Hi", Spans: []Element{&TSpanElement{Content: "!"}}},
+		Raw(Text("raw", 0, 0, Style{})),
+		&GroupElement{Elements: []Element{&TextPathElement{Content: "path", PathID: "p"}}},
+		&GenericElement{Name: "desc", Elements: []Element{CharData("ignored")}},
+	)
+
+	if got := TextContent(doc); got != "Hi!rawpath" {
+		t.Errorf("TextContent = %q, want %q", got, "Hi!rawpath")
+	}
+}
+
+func TestSubsetFont(t *testing.T) {
+	subset, err := SubsetFont(goregular.TTF, "Hé")
+	if err != nil {
+		t.Fatalf("SubsetFont: %v", err)
+	}
+	if len(subset) >= len(goregular.TTF)/2 {
+		t.Errorf("subset is %d bytes, original %d", len(subset), len(goregular.TTF))
+	}
+
+	f, err := sfnt.Parse(subset)
+	if err != nil {
+		t.Fatalf("subset does not parse: %v", err)
+	}
+
+	tests := []struct {
+		r       rune
+		outline bool
+	}{
+		{'H', true},
+		{'é', true}, // composite glyphs keep their components
+		{'Z', false},
+	}
+
+	var buf sfnt.Buffer
+	for _, tt := range tests {
+		t.Run(string(tt.r), func(t *testing.T) {
+			gid, err := f.GlyphIndex(&buf, tt.r)
+			if err != nil || gid == 0 {
+				t.Fatalf("glyph for %q missing: %v", tt.r, err)
+			}
+			segments, err := f.LoadGlyph(&buf, gid, fixed.I(16), nil)
+			if err != nil {
+				t.Fatalf("LoadGlyph: %v", err)
+			}
+			if got := len(segments) > 0; got != tt.outline {
+				t.Errorf("outline for %q = %v, want %v", tt.r, got, tt.outline)
+			}
+		})
+	}
+
+	// Substitutions could map text to emptied glyphs
+	tables, err := readFontTables(subset)
+	if err != nil {
+		t.Fatalf("readFontTables: %v", err)
+	}
+	if _, ok := tables["GSUB"]; ok {
+		t.Error("subset should not keep GSUB")
+	}
+	if _, ok := tables["glyf"]; !ok {
+		t.Error("subset should keep glyf")
+	}
+
+	ss, err := (&StyleSheet{}).EmbedFontSubset("Go", goregular.TTF, "Hé")
+	if err != nil {
+		t.Fatalf("EmbedFontSubset: %v", err)
+	}
+	if len(ss.CSS()) >= base64.StdEncoding.EncodedLen(len(goregular.TTF)) {
+		t.Error("embedded subset should be smaller than the full font")
+	}
+}
+
+func TestSubsetFontInvalid(t *testing.T) {
+	if _, err := SubsetFont([]byte("not a font"), "abc"); err == nil {
+		t.Error("expected error for invalid font data")
+	}
+}
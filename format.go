@@ -0,0 +1,66 @@
+package svg
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// NumberFormat controls how generators write numeric values
+type NumberFormat struct {
+	// Precision is the number of decimal places written
+	Precision int
+
+	// TrimZeros drops trailing zeros, e.g. "10.50" becomes "10.5" and "10.00" becomes "10"
+	TrimZeros bool
+}
+
+// DefaultNumberFormat is the format used unless SetNumberFormat is called
+var DefaultNumberFormat = NumberFormat{Precision: 2}
+
+// Package-wide number format, shared by all generators
+// Stored atomically so it can be changed safely while rendering
+var numberFormat atomic.Pointer[NumberFormat]
+
+// SetNumberFormat sets the number format used by all generators
+// (element helpers, PathBuilder, markers, gradients, ClipPathManager and Renderer)
+func SetNumberFormat(f NumberFormat) {
+	if f.Precision < 0 {
+		f.Precision = 0
+	}
+	numberFormat.Store(&f)
+}
+
+// GetNumberFormat returns the number format currently used by generators
+func GetNumberFormat() NumberFormat {
+	if f := numberFormat.Load(); f != nil {
+		return *f
+	}
+	return DefaultNumberFormat
+}
+
+// Format formats a number according to the format
+func (f NumberFormat) Format(v float64) string {
+	s := strconv.FormatFloat(v, 'f', f.Precision, 64)
+	if f.TrimZeros && strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" || (strings.HasPrefix(s, "-0.") && strings.Trim(s[3:], "0") == "") {
+		// Avoid "-0" and "-0.00" for values that round to zero
+		s = s[1:]
+	}
+	return s
+}
+
+// formatFloat formats a coordinate or length for an attribute value
+func formatFloat(v float64) string {
+	return GetNumberFormat().Format(v)
+}
+
+// formatDimension formats a document dimension; trailing zeros are always trimmed
+func formatDimension(v float64) string {
+	f := GetNumberFormat()
+	f.TrimZeros = true
+	return f.Format(v)
+}
@@ -0,0 +1,58 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		format   NumberFormat
+		value    float64
+		expected string
+	}{
+		{NumberFormat{Precision: 2}, 10, "10.00"},
+		{NumberFormat{Precision: 2, TrimZeros: true}, 10, "10"},
+		{NumberFormat{Precision: 2, TrimZeros: true}, 10.5, "10.5"},
+		{NumberFormat{Precision: 4, TrimZeros: true}, 1.23456, "1.2346"},
+		{NumberFormat{Precision: 0}, 1.6, "2"},
+		{NumberFormat{Precision: 1}, -0.01, "0.0"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.format.Format(tt.value); result != tt.expected {
+			t.Errorf("%+v.Format(%v) = %q, expected %q", tt.format, tt.value, result, tt.expected)
+		}
+	}
+}
+
+func TestSetNumberFormat(t *testing.T) {
+	SetNumberFormat(NumberFormat{Precision: 3, TrimZeros: true})
+	t.Cleanup(func() { SetNumberFormat(DefaultNumberFormat) })
+
+	path := NewPathBuilder().MoveTo(10, 20.5).CurveTo(1.2347, 0, 0, 0, 3, 4).String()
+	if expected := "M 10 20.5 C 1.235 0, 0 0, 3 4"; path != expected {
+		t.Errorf("Expected path %q, got %q", expected, path)
+	}
+
+	rect := Rect(1.5, 2, 3.25, 4, Style{StrokeWidth: 1})
+	if expected := `<rect x="1.5" y="2" width="3.25" height="4" stroke-width="1"/>`; rect != expected {
+		t.Errorf("Expected rect %q, got %q", expected, rect)
+	}
+
+	marker := Marker(MarkerDef{ID: "m", RefX: 5, RefY: 2.5})
+	if !strings.Contains(marker, `refX="5" refY="2.5"`) {
+		t.Errorf("Expected trimmed marker refs, got %q", marker)
+	}
+
+	manager := NewClipPathManager()
+	manager.AddRect(0, 0, 10.125, 20)
+	if defs := manager.ToSVGDefs(); !strings.Contains(defs, `width="10.125"`) {
+		t.Errorf("Expected clipPath to honor precision, got %q", defs)
+	}
+
+	svg := RenderToSVG(nil, Options{Width: 320.5, Height: 200})
+	if !strings.Contains(svg, `width="320.5" height="200" viewBox="0 0 320.5 200"`) {
+		t.Errorf("Expected root dimensions to honor precision, got %q", svg)
+	}
+}
@@ -8,7 +8,9 @@ require (
 	golang.org/x/image v0.35.0
 )
 
-require github.com/SCKelemen/layout v1.1.0 // indirect
+require github.com/SCKelemen/layout v1.1.0
+
+require golang.org/x/text v0.33.0 // indirect
 
 // Exclude problematic test-only dependency (used only in layout tests)
 exclude github.com/SCKelemen/wpt-test-gen v0.0.0-00010101000000-000000000000
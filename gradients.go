@@ -2,7 +2,7 @@ package svg
 
 import (
 	"fmt"
-	"strings"
+	"io"
 
 	"github.com/SCKelemen/color"
 )
@@ -53,94 +53,113 @@ type RadialGradientDef struct {
 	SpreadMethod GradientSpreadMethod
 }
 
-// LinearGradient creates a linear gradient definition (for use in <defs>)
-func LinearGradient(def LinearGradientDef) string {
-	var b strings.Builder
+// StopElement represents an SVG gradient <stop> element
+type StopElement struct {
+	Stop GradientStop
+}
 
-	b.WriteString(fmt.Sprintf(`<linearGradient id="%s"`, def.ID))
+// Tag returns the element name
+func (e *StopElement) Tag() string { return "stop" }
 
-	if def.X1 != "" {
-		b.WriteString(fmt.Sprintf(` x1="%s"`, def.X1))
-	}
-	if def.Y1 != "" {
-		b.WriteString(fmt.Sprintf(` y1="%s"`, def.Y1))
-	}
-	if def.X2 != "" {
-		b.WriteString(fmt.Sprintf(` x2="%s"`, def.X2))
+// Attributes returns the stop attributes
+func (e *StopElement) Attributes() []Attr {
+	attrs := []Attr{
+		{"offset", e.Stop.Offset},
+		{"stop-color", e.Stop.Color},
 	}
-	if def.Y2 != "" {
-		b.WriteString(fmt.Sprintf(` y2="%s"`, def.Y2))
-	}
-	if def.Units != "" {
-		b.WriteString(fmt.Sprintf(` gradientUnits="%s"`, string(def.Units)))
-	}
-	if def.SpreadMethod != "" {
-		b.WriteString(fmt.Sprintf(` spreadMethod="%s"`, string(def.SpreadMethod)))
+	if e.Stop.Opacity > 0 && e.Stop.Opacity < 1 {
+		attrs = append(attrs, Attr{"stop-opacity", formatFloat(e.Stop.Opacity)})
 	}
+	return attrs
+}
 
-	b.WriteString(">")
-	b.WriteString("\n")
+// Children returns no children
+func (e *StopElement) Children() []Element { return nil }
 
-	// Add gradient stops
-	for _, stop := range def.Stops {
-		b.WriteString(fmt.Sprintf(`  <stop offset="%s" stop-color="%s"`, stop.Offset, stop.Color))
-		if stop.Opacity > 0 && stop.Opacity < 1 {
-			b.WriteString(fmt.Sprintf(` stop-opacity="%.2f"`, stop.Opacity))
-		}
-		b.WriteString(`/>`)
-		b.WriteString("\n")
-	}
+// WriteTo writes the stop markup to w
+func (e *StopElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
 
-	b.WriteString(`</linearGradient>`)
-	return b.String()
+// LinearGradientElement represents an SVG <linearGradient> element
+type LinearGradientElement struct {
+	Def LinearGradientDef
 }
 
-// RadialGradient creates a radial gradient definition (for use in <defs>)
-func RadialGradient(def RadialGradientDef) string {
-	var b strings.Builder
+// Tag returns the element name
+func (e *LinearGradientElement) Tag() string { return "linearGradient" }
+
+// Attributes returns the gradient attributes
+func (e *LinearGradientElement) Attributes() []Attr {
+	def := e.Def
+	attrs := []Attr{{"id", def.ID}}
+	attrs = appendNonEmpty(attrs, "x1", def.X1)
+	attrs = appendNonEmpty(attrs, "y1", def.Y1)
+	attrs = appendNonEmpty(attrs, "x2", def.X2)
+	attrs = appendNonEmpty(attrs, "y2", def.Y2)
+	attrs = appendNonEmpty(attrs, "gradientUnits", string(def.Units))
+	attrs = appendNonEmpty(attrs, "spreadMethod", string(def.SpreadMethod))
+	return attrs
+}
 
-	b.WriteString(fmt.Sprintf(`<radialGradient id="%s"`, def.ID))
+// Children returns the gradient stops
+func (e *LinearGradientElement) Children() []Element { return stopElements(e.Def.Stops) }
 
-	if def.CX != "" {
-		b.WriteString(fmt.Sprintf(` cx="%s"`, def.CX))
-	}
-	if def.CY != "" {
-		b.WriteString(fmt.Sprintf(` cy="%s"`, def.CY))
-	}
-	if def.R != "" {
-		b.WriteString(fmt.Sprintf(` r="%s"`, def.R))
-	}
-	if def.FX != "" {
-		b.WriteString(fmt.Sprintf(` fx="%s"`, def.FX))
-	}
-	if def.FY != "" {
-		b.WriteString(fmt.Sprintf(` fy="%s"`, def.FY))
-	}
-	if def.FR != "" {
-		b.WriteString(fmt.Sprintf(` fr="%s"`, def.FR))
-	}
-	if def.Units != "" {
-		b.WriteString(fmt.Sprintf(` gradientUnits="%s"`, string(def.Units)))
-	}
-	if def.SpreadMethod != "" {
-		b.WriteString(fmt.Sprintf(` spreadMethod="%s"`, string(def.SpreadMethod)))
-	}
+// WriteTo writes the gradient markup to w
+func (e *LinearGradientElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
 
-	b.WriteString(">")
-	b.WriteString("\n")
+// RadialGradientElement represents an SVG <radialGradient> element
+type RadialGradientElement struct {
+	Def RadialGradientDef
+}
 
-	// Add gradient stops
-	for _, stop := range def.Stops {
-		b.WriteString(fmt.Sprintf(`  <stop offset="%s" stop-color="%s"`, stop.Offset, stop.Color))
-		if stop.Opacity > 0 && stop.Opacity < 1 {
-			b.WriteString(fmt.Sprintf(` stop-opacity="%.2f"`, stop.Opacity))
-		}
-		b.WriteString(`/>`)
-		b.WriteString("\n")
+// Tag returns the element name
+func (e *RadialGradientElement) Tag() string { return "radialGradient" }
+
+// Attributes returns the gradient attributes
+func (e *RadialGradientElement) Attributes() []Attr {
+	def := e.Def
+	attrs := []Attr{{"id", def.ID}}
+	attrs = appendNonEmpty(attrs, "cx", def.CX)
+	attrs = appendNonEmpty(attrs, "cy", def.CY)
+	attrs = appendNonEmpty(attrs, "r", def.R)
+	attrs = appendNonEmpty(attrs, "fx", def.FX)
+	attrs = appendNonEmpty(attrs, "fy", def.FY)
+	attrs = appendNonEmpty(attrs, "fr", def.FR)
+	attrs = appendNonEmpty(attrs, "gradientUnits", string(def.Units))
+	attrs = appendNonEmpty(attrs, "spreadMethod", string(def.SpreadMethod))
+	return attrs
+}
+
+// Children returns the gradient stops
+func (e *RadialGradientElement) Children() []Element { return stopElements(e.Def.Stops) }
+
+// WriteTo writes the gradient markup to w
+func (e *RadialGradientElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// stopElements converts gradient stops to stop elements
+func stopElements(stops []GradientStop) []Element {
+	elements := make([]Element, len(stops))
+	for i, stop := range stops {
+		elements[i] = &StopElement{Stop: stop}
+	}
+	return elements
+}
+
+// appendNonEmpty appends an attribute only when its value is set
+func appendNonEmpty(attrs []Attr, name, value string) []Attr {
+	if value == "" {
+		return attrs
 	}
+	return append(attrs, Attr{name, value})
+}
 
-	b.WriteString(`</radialGradient>`)
-	return b.String()
+// LinearGradient creates a linear gradient definition (for use in <defs>)
+func LinearGradient(def LinearGradientDef) string {
+	return Serialize(&LinearGradientElement{Def: def})
+}
+
+// RadialGradient creates a radial gradient definition (for use in <defs>)
+func RadialGradient(def RadialGradientDef) string {
+	return Serialize(&RadialGradientElement{Def: def})
 }
 
 // GradientURL creates a url() reference to a gradient for use in fill or stroke
@@ -241,7 +260,7 @@ func InterpolatedLinearGradient(id string, startColor, endColor string, angle fl
 		hexColor := color.RGBToHex(colors[i])
 
 		stops[i] = GradientStop{
-			Offset:  fmt.Sprintf("%.1f%%", t*100),
+			Offset:  formatFloat(t*100) + "%",
 			Color:   hexColor,
 			Opacity: 1.0,
 		}
@@ -296,7 +315,7 @@ func InterpolatedRadialGradient(id string, centerColor, edgeColor string, steps
 		hexColor := color.RGBToHex(colors[i])
 
 		stops[i] = GradientStop{
-			Offset:  fmt.Sprintf("%.1f%%", t*100),
+			Offset:  formatFloat(t*100) + "%",
 			Color:   hexColor,
 			Opacity: 1.0,
 		}
@@ -0,0 +1,89 @@
+package svg
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// IDAllocator hands out element IDs such as "clip-1" and "gradient-2"
+//
+// Each kind of ID is numbered from 1 in the order it is requested, so the
+// same sequence of calls always produces the same IDs. IDs are unique within
+// one allocator; use a Prefix, or a unique allocator, to keep the IDs of
+// several SVGs apart when they are inlined into one HTML page.
+type IDAllocator struct {
+	// Prefix is prepended to every ID (optional)
+	Prefix string
+
+	mu       sync.Mutex
+	counters map[string]int
+	reserved map[string]bool
+}
+
+// NewIDAllocator creates an allocator with deterministic numbering
+func NewIDAllocator(prefix string) *IDAllocator {
+	return &IDAllocator{Prefix: prefix}
+}
+
+// NewUniqueIDAllocator creates an allocator whose IDs are safe to mix with
+// those of other documents: prefix is followed by a random token
+// The numbering is still deterministic, but the token differs on every call
+func NewUniqueIDAllocator(prefix string) *IDAllocator {
+	return &IDAllocator{Prefix: prefix + randomToken() + "-"}
+}
+
+// Next returns the next unused ID of the given kind
+func (a *IDAllocator) Next(kind string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.counters == nil {
+		a.counters = make(map[string]int)
+	}
+	for {
+		a.counters[kind]++
+		id := fmt.Sprintf("%s%s-%d", a.Prefix, kind, a.counters[kind])
+		if !a.reserved[id] {
+			return id
+		}
+	}
+}
+
+// Reserve marks an ID as used, so Next never returns it
+func (a *IDAllocator) Reserve(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.reserved == nil {
+		a.reserved = make(map[string]bool)
+	}
+	a.reserved[id] = true
+}
+
+// Reset restarts the numbering of every kind and forgets reserved IDs
+func (a *IDAllocator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.counters = nil
+	a.reserved = nil
+}
+
+// newIDAllocator returns the allocator configured by the options
+func newIDAllocator(opts Options) *IDAllocator {
+	if opts.UniqueIDs {
+		return NewUniqueIDAllocator(opts.IDPrefix)
+	}
+	return NewIDAllocator(opts.IDPrefix)
+}
+
+// randomToken returns a short random token that starts with a letter,
+// so IDs built from it are valid CSS selectors
+func randomToken() string {
+	var b [5]byte
+	rand.Read(b[:])
+	n := uint64(b[0])<<32 | uint64(b[1])<<24 | uint64(b[2])<<16 | uint64(b[3])<<8 | uint64(b[4])
+	return "u" + strconv.FormatUint(n, 36)
+}
@@ -0,0 +1,119 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestIDAllocator(t *testing.T) {
+	ids := NewIDAllocator("chart-")
+
+	tests := []struct {
+		kind     string
+		expected string
+	}{
+		{"clip", "chart-clip-1"},
+		{"clip", "chart-clip-2"},
+		{"gradient", "chart-gradient-1"},
+		{"clip", "chart-clip-3"},
+	}
+	for _, tt := range tests {
+		if got := ids.Next(tt.kind); got != tt.expected {
+			t.Errorf("Next(%q) = %q, want %q", tt.kind, got, tt.expected)
+		}
+	}
+
+	ids.Reset()
+	ids.Reserve("chart-clip-1")
+	if got := ids.Next("clip"); got != "chart-clip-2" {
+		t.Errorf("Next should skip reserved IDs, got %q", got)
+	}
+}
+
+func TestUniqueIDAllocator(t *testing.T) {
+	a, b := NewUniqueIDAllocator("svg-"), NewUniqueIDAllocator("svg-")
+	if a.Prefix == b.Prefix {
+		t.Errorf("unique allocators share the prefix %q", a.Prefix)
+	}
+	if !strings.HasPrefix(a.Next("clip"), "svg-u") {
+		t.Errorf("unexpected unique ID prefix %q", a.Prefix)
+	}
+}
+
+func TestRenderDeterministicIDs(t *testing.T) {
+	node := &layout.Node{
+		Rect:     layout.Rect{Width: 50, Height: 50},
+		Children: []*layout.Node{{Rect: layout.Rect{Width: 100, Height: 100}}},
+	}
+	opts := DefaultOptions()
+	opts.BoxFunc = func(n *layout.Node, depth int) (BoxStyle, bool) {
+		return BoxStyle{Overflow: OverflowHidden, Shadows: []BoxShadow{{Blur: 4, Color: "#000"}}}, depth == 0
+	}
+
+	first := RenderToSVG(node, opts)
+	if second := RenderToSVG(node, opts); first != second {
+		t.Errorf("renders differ:\n%s\n%s", first, second)
+	}
+	for _, expected := range []string{`id="clip-1"`, `id="shadow-1"`} {
+		if !strings.Contains(first, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, first)
+		}
+	}
+
+	opts.IDPrefix = "a-"
+	if svg := RenderToSVG(node, opts); !strings.Contains(svg, `id="a-clip-1"`) || !strings.Contains(svg, "url(#a-clip-1)") {
+		t.Errorf("Expected prefixed IDs in:\n%s", svg)
+	}
+
+	opts.UniqueIDs = true
+	if RenderToSVG(node, opts) == RenderToSVG(node, opts) {
+		t.Error("unique IDs should differ between renders")
+	}
+}
+
+func TestRenderTwiceWithSameRenderer(t *testing.T) {
+	node := &layout.Node{
+		Rect:     layout.Rect{Width: 50, Height: 50},
+		Children: []*layout.Node{{Rect: layout.Rect{Width: 100, Height: 100}}},
+	}
+	opts := DefaultOptions()
+	opts.Title = "Chart"
+	opts.BoxFunc = func(n *layout.Node, depth int) (BoxStyle, bool) {
+		return BoxStyle{Overflow: OverflowHidden}, depth == 0
+	}
+
+	renderer := NewRenderer(opts)
+	custom := renderer.GetClipPathManager().AddCircle(5, 5, 5)
+	first := renderer.Render(node)
+	if second := renderer.Render(node); first != second {
+		t.Errorf("renders differ:\n%s\n%s", first, second)
+	}
+
+	var b strings.Builder
+	renderer.RenderTo(&b, node)
+	renderer.RenderTo(&b, node)
+	streamed := b.String()
+	if half := len(streamed) / 2; streamed[:half] != streamed[half:] {
+		t.Errorf("streamed renders differ:\n%s", streamed)
+	}
+
+	// Registered clip paths are kept and generated IDs avoid them
+	if strings.Count(first, "<clipPath") != 2 || !strings.Contains(first, `id="`+custom+`"`) {
+		t.Errorf("Expected the registered and generated clip paths in:\n%s", first)
+	}
+	for _, expected := range []string{`clip-path="url(#clip-2)"`, `id="title-1"`} {
+		if !strings.Contains(first, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, first)
+		}
+	}
+}
+
+func TestDocumentIDPrefix(t *testing.T) {
+	doc := NewDocument(10, 10)
+	doc.IDs = NewIDAllocator("doc-")
+	if got := doc.NewID("marker"); got != "doc-marker-1" {
+		t.Errorf("NewID = %q, want doc-marker-1", got)
+	}
+}
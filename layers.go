@@ -0,0 +1,78 @@
+package svg
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Layer is a collection of positioned nodes drawn as one group
+type Layer struct {
+	// Name is the id of the layer's <g> (optional)
+	// Generated IDs, such as those of clip paths, never reuse it
+	// Layers without a name or attributes are drawn without a group
+	Name string
+
+	// Z orders the layers: higher layers are drawn over lower ones, and
+	// layers with the same Z keep their order
+	Z int
+
+	// Nodes are drawn at their computed positions, in order
+	Nodes []*layout.Node
+
+	// Attrs are further attributes of the group; unsafe ones are dropped
+	Attrs []Attr
+}
+
+// attrs returns the attributes of the layer's group
+func (l Layer) attrs() []Attr {
+	return SafeAttrs(append(appendNonEmpty(nil, "id", l.Name), l.Attrs...)...)
+}
+
+// RenderLayers renders layers of positioned nodes, in z-order
+func RenderLayers(layers []Layer, opts Options) string {
+	renderer := NewRenderer(opts)
+	return renderer.RenderLayers(layers)
+}
+
+// RenderLayers renders layers of positioned nodes, in z-order
+// Nodes are numbered across layers in the order given, so node paths such
+// as data-node stay the same when layers are reordered
+func (r *Renderer) RenderLayers(layers []Layer) string {
+	type indexedLayer struct {
+		Layer
+		first int // Index of the layer's first node
+	}
+	ordered := make([]indexedLayer, len(layers))
+	first := 0
+	for i, layer := range layers {
+		ordered[i] = indexedLayer{Layer: layer, first: first}
+		first += len(layer.Nodes)
+	}
+	slices.SortStableFunc(ordered, func(a, b indexedLayer) int { return cmp.Compare(a.Z, b.Z) })
+
+	var ids []string
+	for _, layer := range layers {
+		if id := attrValue(layer.attrs(), "id"); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	return r.document(func(nw *nodeWriter) {
+		for _, layer := range ordered {
+			attrs := layer.attrs()
+			if len(attrs) > 0 {
+				nw.write("<g")
+				nw.write(formatAttrs(attrs))
+				nw.write(">\n")
+			}
+			for i, node := range layer.Nodes {
+				r.renderNode(nw, node, layer.first+i)
+			}
+			if len(attrs) > 0 {
+				nw.write("</g>\n")
+			}
+		}
+	}, ids...)
+}
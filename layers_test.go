@@ -0,0 +1,89 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestRenderNodesMatchesRender(t *testing.T) {
+	node := &layout.Node{Rect: layout.Rect{X: 5, Y: 5, Width: 50, Height: 50}}
+	opts := DefaultOptions()
+	opts.ViewBox = "0 0 100 100"
+	opts.PreserveAspectRatio = "xMinYMin slice"
+	opts.IncludeXMLDeclaration = true
+	opts.Title = "Chart"
+
+	got := RenderNodes([]*layout.Node{node}, opts)
+	want := RenderToSVG(node, opts)
+	if got != want {
+		t.Errorf("Expected RenderNodes to match Render for one node:\n%s\nGot:\n%s", want, got)
+	}
+	for _, expected := range []string{`<?xml version="1.0"`, `viewBox="0 0 100 100"`, `preserveAspectRatio="xMinYMin slice"`} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, got)
+		}
+	}
+}
+
+func TestRenderLayers(t *testing.T) {
+	node := func(x float64) *layout.Node {
+		return &layout.Node{Rect: layout.Rect{X: x, Width: 10, Height: 10}}
+	}
+	layers := []Layer{
+		{Name: "labels", Z: 2, Nodes: []*layout.Node{node(1)}},
+		{Name: "data", Z: 1, Nodes: []*layout.Node{node(2), node(3)}, Attrs: []Attr{{"opacity", "0.5"}, {"onclick", "alert(1)"}}},
+		{Nodes: []*layout.Node{node(4)}},
+	}
+	opts := DefaultOptions()
+	opts.NodeIDs = true
+
+	svg := RenderLayers(layers, opts)
+
+	// Layers are drawn from the lowest Z up
+	unnamed := strings.Index(svg, `x="4.00"`)
+	data := strings.Index(svg, `<g id="data" opacity="0.5">`)
+	labels := strings.Index(svg, `<g id="labels">`)
+	if unnamed < 0 || data < unnamed || labels < data {
+		t.Errorf("Expected layers in z-order:\n%s", svg)
+	}
+	if strings.Contains(svg, "onclick") {
+		t.Errorf("Expected unsafe layer attributes to be dropped:\n%s", svg)
+	}
+
+	// Nodes are numbered in the order the layers were given
+	for _, expected := range []string{`data-node="0"><rect x="1.00"`, `data-node="2"><rect x="3.00"`, `data-node="3"><rect x="4.00"`} {
+		if !strings.Contains(strings.ReplaceAll(svg, ">\n  ", ">"), expected) {
+			t.Errorf("Expected %q in:\n%s", expected, svg)
+		}
+	}
+}
+
+func TestRenderLayersReservesNames(t *testing.T) {
+	node := &layout.Node{
+		Rect:     layout.Rect{Width: 10, Height: 10},
+		Children: []*layout.Node{{Rect: layout.Rect{Width: 20, Height: 20}}},
+	}
+	opts := DefaultOptions()
+	opts.Title = "Chart"
+	opts.BoxFunc = func(n *layout.Node, depth int) (BoxStyle, bool) {
+		return BoxStyle{Overflow: OverflowHidden}, depth == 0
+	}
+
+	svg := RenderLayers([]Layer{
+		{Name: "clip-1", Nodes: []*layout.Node{node}},
+		{Attrs: []Attr{{"id", "title-1"}}},
+	}, opts)
+
+	for _, id := range []string{"clip-1", "title-1"} {
+		if n := strings.Count(svg, `id="`+id+`"`); n != 1 {
+			t.Errorf("Expected one element with id %q, got %d:\n%s", id, n, svg)
+		}
+	}
+	for _, expected := range []string{`clip-path="url(#clip-2)"`, `aria-labelledby="title-2"`} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, svg)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package svg
 
 import (
 	"fmt"
-	"strings"
+	"io"
 )
 
 // MarkerUnits defines the coordinate system for marker dimensions
@@ -34,40 +34,58 @@ type MarkerDef struct {
 	Content      string // SVG content inside the marker
 }
 
-// Marker creates a marker definition (for use in <defs>)
-func Marker(def MarkerDef) string {
-	var b strings.Builder
+// MarkerElement represents an SVG <marker> element
+// Def.Content is written verbatim before any child elements
+type MarkerElement struct {
+	Def      MarkerDef
+	Elements []Element
+}
 
-	b.WriteString(fmt.Sprintf(`<marker id="%s"`, def.ID))
+// Tag returns the element name
+func (e *MarkerElement) Tag() string { return "marker" }
+
+// Attributes returns the marker attributes
+func (e *MarkerElement) Attributes() []Attr {
+	def := e.Def
+	attrs := []Attr{{"id", def.ID}}
 
 	if def.ViewBox != "" {
-		b.WriteString(fmt.Sprintf(` viewBox="%s"`, def.ViewBox))
+		attrs = append(attrs, Attr{"viewBox", def.ViewBox})
 	}
 
-	b.WriteString(fmt.Sprintf(` refX="%.2f" refY="%.2f"`, def.RefX, def.RefY))
+	attrs = append(attrs, Attr{"refX", formatFloat(def.RefX)}, Attr{"refY", formatFloat(def.RefY)})
 
 	if def.MarkerWidth > 0 {
-		b.WriteString(fmt.Sprintf(` markerWidth="%.2f"`, def.MarkerWidth))
+		attrs = append(attrs, Attr{"markerWidth", formatFloat(def.MarkerWidth)})
 	}
 	if def.MarkerHeight > 0 {
-		b.WriteString(fmt.Sprintf(` markerHeight="%.2f"`, def.MarkerHeight))
+		attrs = append(attrs, Attr{"markerHeight", formatFloat(def.MarkerHeight)})
 	}
-
 	if def.Orient != "" {
-		b.WriteString(fmt.Sprintf(` orient="%s"`, string(def.Orient)))
+		attrs = append(attrs, Attr{"orient", string(def.Orient)})
 	}
-
 	if def.MarkerUnits != "" {
-		b.WriteString(fmt.Sprintf(` markerUnits="%s"`, string(def.MarkerUnits)))
+		attrs = append(attrs, Attr{"markerUnits", string(def.MarkerUnits)})
 	}
 
-	b.WriteString(">")
-	b.WriteString("\n")
-	b.WriteString(def.Content)
-	b.WriteString("\n")
-	b.WriteString("</marker>")
+	return attrs
+}
 
-	return b.String()
+// Children returns the marker content
+func (e *MarkerElement) Children() []Element {
+	var children []Element
+	if e.Def.Content != "" {
+		children = append(children, Raw(e.Def.Content))
+	}
+	return append(children, e.Elements...)
+}
+
+// WriteTo writes the marker markup to w
+func (e *MarkerElement) WriteTo(w io.Writer) (int64, error) { return writeElement(w, e) }
+
+// Marker creates a marker definition (for use in <defs>)
+func Marker(def MarkerDef) string {
+	return Serialize(&MarkerElement{Def: def})
 }
 
 // MarkerURL creates a url() reference to a marker
@@ -93,7 +111,7 @@ func StyleWithMarkers(style Style, markers MarkerStyle) Style {
 
 // ArrowMarker creates a simple arrow marker pointing right
 func ArrowMarker(id string, color string) string {
-	content := fmt.Sprintf(`<path d="M 0 0 L 10 5 L 0 10 Z" fill="%s"/>`, color)
+	content := fmt.Sprintf(`<path d="M 0 0 L 10 5 L 0 10 Z" fill="%s"/>`, escapeXML(color))
 	return Marker(MarkerDef{
 		ID:           id,
 		ViewBox:      "0 0 10 10",
@@ -108,7 +126,7 @@ func ArrowMarker(id string, color string) string {
 
 // CircleMarker creates a circular marker
 func CircleMarker(id string, color string) string {
-	content := fmt.Sprintf(`<circle cx="5" cy="5" r="4" fill="%s"/>`, color)
+	content := fmt.Sprintf(`<circle cx="5" cy="5" r="4" fill="%s"/>`, escapeXML(color))
 	return Marker(MarkerDef{
 		ID:           id,
 		ViewBox:      "0 0 10 10",
@@ -123,7 +141,7 @@ func CircleMarker(id string, color string) string {
 
 // SquareMarker creates a square marker
 func SquareMarker(id string, color string) string {
-	content := fmt.Sprintf(`<rect x="1" y="1" width="8" height="8" fill="%s"/>`, color)
+	content := fmt.Sprintf(`<rect x="1" y="1" width="8" height="8" fill="%s"/>`, escapeXML(color))
 	return Marker(MarkerDef{
 		ID:           id,
 		ViewBox:      "0 0 10 10",
@@ -138,7 +156,7 @@ func SquareMarker(id string, color string) string {
 
 // DiamondMarker creates a diamond marker
 func DiamondMarker(id string, color string) string {
-	content := fmt.Sprintf(`<path d="M 5 1 L 9 5 L 5 9 L 1 5 Z" fill="%s"/>`, color)
+	content := fmt.Sprintf(`<path d="M 5 1 L 9 5 L 5 9 L 1 5 Z" fill="%s"/>`, escapeXML(color))
 	return Marker(MarkerDef{
 		ID:           id,
 		ViewBox:      "0 0 10 10",
@@ -153,7 +171,7 @@ func DiamondMarker(id string, color string) string {
 
 // TriangleMarker creates a triangle marker
 func TriangleMarker(id string, color string) string {
-	content := fmt.Sprintf(`<path d="M 5 1 L 9 9 L 1 9 Z" fill="%s"/>`, color)
+	content := fmt.Sprintf(`<path d="M 5 1 L 9 9 L 1 9 Z" fill="%s"/>`, escapeXML(color))
 	return Marker(MarkerDef{
 		ID:           id,
 		ViewBox:      "0 0 10 10",
@@ -168,7 +186,7 @@ func TriangleMarker(id string, color string) string {
 
 // CrossMarker creates a cross/plus marker
 func CrossMarker(id string, color string, strokeWidth float64) string {
-	content := fmt.Sprintf(`<path d="M 5 1 L 5 9 M 1 5 L 9 5" stroke="%s" stroke-width="%.1f" stroke-linecap="round"/>`, color, strokeWidth)
+	content := fmt.Sprintf(`<path d="M 5 1 L 5 9 M 1 5 L 9 5" stroke="%s" stroke-width="%s" stroke-linecap="round"/>`, escapeXML(color), formatFloat(strokeWidth))
 	return Marker(MarkerDef{
 		ID:           id,
 		ViewBox:      "0 0 10 10",
@@ -183,7 +201,7 @@ func CrossMarker(id string, color string, strokeWidth float64) string {
 
 // XMarker creates an X marker
 func XMarker(id string, color string, strokeWidth float64) string {
-	content := fmt.Sprintf(`<path d="M 2 2 L 8 8 M 8 2 L 2 8" stroke="%s" stroke-width="%.1f" stroke-linecap="round"/>`, color, strokeWidth)
+	content := fmt.Sprintf(`<path d="M 2 2 L 8 8 M 8 2 L 2 8" stroke="%s" stroke-width="%s" stroke-linecap="round"/>`, escapeXML(color), formatFloat(strokeWidth))
 	return Marker(MarkerDef{
 		ID:           id,
 		ViewBox:      "0 0 10 10",
@@ -198,7 +216,7 @@ func XMarker(id string, color string, strokeWidth float64) string {
 
 // DotMarker creates a small dot marker (good for data points)
 func DotMarker(id string, color string, radius float64) string {
-	content := fmt.Sprintf(`<circle cx="5" cy="5" r="%.1f" fill="%s"/>`, radius, color)
+	content := fmt.Sprintf(`<circle cx="5" cy="5" r="%s" fill="%s"/>`, formatFloat(radius), escapeXML(color))
 	return Marker(MarkerDef{
 		ID:           id,
 		ViewBox:      "0 0 10 10",
@@ -211,32 +229,29 @@ func DotMarker(id string, color string, radius float64) string {
 	})
 }
 
-// Helper to apply markers to path style attributes
-func applyMarkers(style Style, markerStart, markerMid, markerEnd string) string {
-	attrs := formatStyle(style)
+// MarkerAttrs returns marker reference attributes for use in an element's Attrs
+func MarkerAttrs(markerStart, markerMid, markerEnd string) []Attr {
+	var attrs []Attr
 	if markerStart != "" {
-		attrs += fmt.Sprintf(` marker-start="%s"`, markerStart)
+		attrs = append(attrs, Attr{"marker-start", markerStart})
 	}
 	if markerMid != "" {
-		attrs += fmt.Sprintf(` marker-mid="%s"`, markerMid)
+		attrs = append(attrs, Attr{"marker-mid", markerMid})
 	}
 	if markerEnd != "" {
-		attrs += fmt.Sprintf(` marker-end="%s"`, markerEnd)
+		attrs = append(attrs, Attr{"marker-end", markerEnd})
 	}
 	return attrs
 }
 
 // PathWithMarkers renders a path with marker references
 func PathWithMarkers(d string, style Style, markerStart, markerMid, markerEnd string) string {
-	attrs := applyMarkers(style, markerStart, markerMid, markerEnd)
-	return fmt.Sprintf(`<path d="%s"%s/>`, d, attrs)
+	return Serialize(&PathElement{D: d, Style: style, Attrs: MarkerAttrs(markerStart, markerMid, markerEnd)})
 }
 
 // LineWithMarkers renders a line with marker references
 func LineWithMarkers(x1, y1, x2, y2 float64, style Style, markerStart, markerEnd string) string {
-	attrs := applyMarkers(style, markerStart, "", markerEnd)
-	return fmt.Sprintf(`<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f"%s/>`,
-		x1, y1, x2, y2, attrs)
+	return Serialize(&LineElement{X1: x1, Y1: y1, X2: x2, Y2: y2, Style: style, Attrs: MarkerAttrs(markerStart, "", markerEnd)})
 }
 
 // PolylineWithMarkers renders a polyline with marker references
@@ -244,15 +259,5 @@ func PolylineWithMarkers(points []Point, style Style, markerStart, markerMid, ma
 	if len(points) == 0 {
 		return ""
 	}
-
-	var pointsStr strings.Builder
-	for i, p := range points {
-		if i > 0 {
-			pointsStr.WriteString(" ")
-		}
-		fmt.Fprintf(&pointsStr, "%.2f,%.2f", p.X, p.Y)
-	}
-
-	attrs := applyMarkers(style, markerStart, markerMid, markerEnd)
-	return fmt.Sprintf(`<polyline points="%s"%s/>`, pointsStr.String(), attrs)
+	return Serialize(&PolylineElement{Points: points, Style: style, Attrs: MarkerAttrs(markerStart, markerMid, markerEnd)})
 }
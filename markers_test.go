@@ -161,7 +161,7 @@ func TestCrossMarker(t *testing.T) {
 		`id="cross-black"`,
 		`#000000`,
 		`stroke="#000000"`,
-		`stroke-width="1.5"`,
+		`stroke-width="1.50"`,
 		`<path`,
 	}
 
@@ -179,7 +179,7 @@ func TestXMarker(t *testing.T) {
 		`id="x-red"`,
 		`#FF0000`,
 		`stroke="#FF0000"`,
-		`stroke-width="2.0"`,
+		`stroke-width="2.00"`,
 		`<path`,
 	}
 
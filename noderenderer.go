@@ -0,0 +1,139 @@
+package svg
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// NodeRenderer extends how layout nodes are rendered
+//
+// Renderers in Options.NodeRenderers are called in order for every node:
+//
+//   - Before may adjust ctx.Style and returns elements drawn under the node
+//   - Render may replace the node's box and text; the first renderer that
+//     returns true wins
+//   - Children returns the children to render, given the current list
+//   - After returns elements drawn over the node and its children
+//
+// Embed NodeHooks, or use it directly, to implement only some of the hooks.
+type NodeRenderer interface {
+	Before(node *layout.Node, ctx *RenderContext) []Element
+	Render(node *layout.Node, ctx *RenderContext) ([]Element, bool)
+	Children(node *layout.Node, children []*layout.Node, ctx *RenderContext) []*layout.Node
+	After(node *layout.Node, ctx *RenderContext) []Element
+}
+
+// RenderContext describes the node being rendered
+type RenderContext struct {
+	// Depth of the node; top-level nodes have depth 0
+	Depth int
+
+	// Path holds the nodes from the root down to and including the node
+	Path []*layout.Node
+
+	// Style is the node's computed style; Before hooks may change it
+	Style Style
+
+	// ParentStyle is the computed style of the parent node
+	ParentStyle Style
+
+	// Renderer is the renderer producing the output
+	Renderer *Renderer
+
+	index       []int  // Position of each node of Path among its rendered siblings
+	transformed bool   // The node or one of its ancestors has a transform
+	transform   string // Transforms from the root down to the node's group
+}
+
+// Parent returns the parent of the node, or nil for a top-level node
+func (c *RenderContext) Parent() *layout.Node {
+	if len(c.Path) < 2 {
+		return nil
+	}
+	return c.Path[len(c.Path)-2]
+}
+
+// NewID returns a unique ID of the given kind for the current output
+func (c *RenderContext) NewID(kind string) string {
+	return c.Renderer.ids.Next(kind)
+}
+
+// AddDef adds a definition, such as a gradient or filter, to <defs>
+// It should have an ID from NewID; a definition whose ID was already added
+// is dropped
+func (c *RenderContext) AddDef(def Element) {
+	c.Renderer.addDef(def)
+}
+
+// ClipPaths returns the manager for clip paths of the current render,
+// written to <defs>
+func (c *RenderContext) ClipPaths() *ClipPathManager {
+	return c.Renderer.clips
+}
+
+// NodeHooks implements NodeRenderer with optional functions
+// Hooks that are nil do nothing
+type NodeHooks struct {
+	BeforeFunc   func(node *layout.Node, ctx *RenderContext) []Element
+	RenderFunc   func(node *layout.Node, ctx *RenderContext) ([]Element, bool)
+	ChildrenFunc func(node *layout.Node, children []*layout.Node, ctx *RenderContext) []*layout.Node
+	AfterFunc    func(node *layout.Node, ctx *RenderContext) []Element
+}
+
+// Before calls BeforeFunc
+func (h NodeHooks) Before(node *layout.Node, ctx *RenderContext) []Element {
+	if h.BeforeFunc == nil {
+		return nil
+	}
+	return h.BeforeFunc(node, ctx)
+}
+
+// Render calls RenderFunc
+func (h NodeHooks) Render(node *layout.Node, ctx *RenderContext) ([]Element, bool) {
+	if h.RenderFunc == nil {
+		return nil, false
+	}
+	return h.RenderFunc(node, ctx)
+}
+
+// Children calls ChildrenFunc
+func (h NodeHooks) Children(node *layout.Node, children []*layout.Node, ctx *RenderContext) []*layout.Node {
+	if h.ChildrenFunc == nil {
+		return children
+	}
+	return h.ChildrenFunc(node, children, ctx)
+}
+
+// After calls AfterFunc
+func (h NodeHooks) After(node *layout.Node, ctx *RenderContext) []Element {
+	if h.AfterFunc == nil {
+		return nil
+	}
+	return h.AfterFunc(node, ctx)
+}
+
+// childContext returns the context of the i-th child of the node
+func (c *RenderContext) childContext(child *layout.Node, i int) *RenderContext {
+	return &RenderContext{
+		Depth:       c.Depth + 1,
+		Path:        append(slices.Clip(c.Path), child),
+		ParentStyle: c.Style,
+		Renderer:    c.Renderer,
+		index:       append(slices.Clip(c.index), i),
+		transformed: c.transformed,
+		transform:   c.transform,
+	}
+}
+
+// nodePath identifies the node by its position in the tree, e.g. "0.2.1"
+// for the second child of the third child of the first top-level node
+func (c *RenderContext) nodePath() string {
+	parts := make([]string, len(c.index))
+	for i, n := range c.index {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,82 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestNodeRenderers(t *testing.T) {
+	leaf := &layout.Node{Rect: layout.Rect{X: 10, Y: 10, Width: 20, Height: 20}}
+	hidden := &layout.Node{Rect: layout.Rect{X: 40, Y: 10, Width: 20, Height: 20}}
+	root := &layout.Node{
+		Rect:     layout.Rect{Width: 100, Height: 50},
+		Children: []*layout.Node{leaf, hidden},
+	}
+
+	var paths []int
+	var parentFills []string
+	badge := NodeHooks{
+		BeforeFunc: func(node *layout.Node, ctx *RenderContext) []Element {
+			paths = append(paths, len(ctx.Path))
+			parentFills = append(parentFills, ctx.ParentStyle.Fill)
+			ctx.Style = Style{Fill: "#0000ff"}
+			return []Element{Comment("before")}
+		},
+		ChildrenFunc: func(node *layout.Node, children []*layout.Node, ctx *RenderContext) []*layout.Node {
+			var kept []*layout.Node
+			for _, child := range children {
+				if child != hidden {
+					kept = append(kept, child)
+				}
+			}
+			return kept
+		},
+		AfterFunc: func(node *layout.Node, ctx *RenderContext) []Element {
+			if ctx.Parent() != root {
+				return nil
+			}
+			id := ctx.NewID("badge")
+			ctx.AddDef(&GenericElement{Name: "symbol", Attrs: []Attr{{"id", id}}})
+			return []Element{&GenericElement{Name: "use", Attrs: []Attr{{"href", "#" + id}}}}
+		},
+	}
+	replace := NodeHooks{
+		RenderFunc: func(node *layout.Node, ctx *RenderContext) ([]Element, bool) {
+			if node != leaf {
+				return nil, false
+			}
+			return []Element{&CircleElement{CX: 20, CY: 20, R: 10, Style: ctx.Style}}, true
+		},
+	}
+
+	opts := DefaultOptions()
+	opts.NodeRenderers = []NodeRenderer{badge, replace}
+	svg := RenderToSVG(root, opts)
+
+	for _, expected := range []string{
+		"<!--before-->",
+		`<rect x="0.00" y="0.00" width="100.00" height="50.00" fill="#0000ff"/>`,
+		`<circle cx="20.00" cy="20.00" r="10.00" fill="#0000ff"/>`,
+		`<symbol id="badge-1"`,
+		`<use href="#badge-1"`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, svg)
+		}
+	}
+	if strings.Contains(svg, `x="40.00"`) {
+		t.Errorf("Children hook should have removed a node:\n%s", svg)
+	}
+	if strings.Contains(svg, `<rect x="10.00"`) {
+		t.Errorf("Render hook should replace the default box:\n%s", svg)
+	}
+
+	if len(paths) != 2 || paths[0] != 1 || paths[1] != 2 {
+		t.Errorf("path lengths = %v, want [1 2]", paths)
+	}
+	if parentFills[1] != "#0000ff" {
+		t.Errorf("child should see the parent's computed style, got %q", parentFills[1])
+	}
+}
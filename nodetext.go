@@ -0,0 +1,331 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+	"github.com/SCKelemen/units"
+)
+
+// VerticalAlign positions text vertically within a node's content box
+type VerticalAlign string
+
+const (
+	VerticalAlignTop    VerticalAlign = "top"
+	VerticalAlignMiddle VerticalAlign = "middle"
+	VerticalAlignBottom VerticalAlign = "bottom"
+)
+
+// Overflow controls whether content outside a node's box is shown
+type Overflow string
+
+const (
+	OverflowVisible Overflow = "visible"
+	OverflowHidden  Overflow = "hidden" // Clip to the node's content box
+)
+
+// NodeText is text rendered inside a layout node's content box
+type NodeText struct {
+	// Content is the text; if empty, the node's Text is used
+	Content string
+
+	// Style is applied over the node's text style (font, fill, ...)
+	Style Style
+
+	// Align positions lines horizontally
+	// If empty, the node's TextStyle.TextAlign is used
+	Align TextAnchor
+
+	// VerticalAlign positions the text block (default top)
+	VerticalAlign VerticalAlign
+
+	// Overflow hidden clips the text (see OverflowHidden); with
+	// text-overflow: ellipsis, lines that do not fit are also dropped
+	// If empty, the node's BoxStyle.Overflow is used
+	Overflow Overflow
+
+	// Block configures wrapping; zero fields fall back to the node's TextStyle
+	Block TextBlockOptions
+}
+
+// renderNodeText renders the text of a node, or returns "" if it has none
+// overflow is the node's box overflow, and clip returns its content clip path
+func (r *Renderer) renderNodeText(node *layout.Node, depth int, overflow Overflow, clip func() string) string {
+	var text NodeText
+	if r.options.TextFunc != nil {
+		if t, ok := r.options.TextFunc(node, depth); ok {
+			text = t
+		}
+	}
+	if text.Overflow == "" {
+		text.Overflow = overflow
+	}
+	if text.Content == "" {
+		text.Content = node.Text
+	}
+	if text.Content == "" {
+		return ""
+	}
+
+	box := r.BoxModel(node)
+	style := mergeStyle(r.nodeTextStyle(node), text.Style)
+
+	var element *TextElement
+	if text.Content == node.Text && node.TextLayout != nil && len(node.TextLayout.Lines) > 0 {
+		// Lines were already broken by the layout engine
+		element = textLayoutElement(node.TextLayout, box.Content, style, text.VerticalAlign)
+	} else {
+		element = r.textBlockElement(node, text, box.Content, style)
+	}
+	if element == nil {
+		return ""
+	}
+
+	if text.Overflow != OverflowHidden {
+		return Serialize(element)
+	}
+	return Serialize(&GroupElement{
+		Style:    Style{ClipPath: URL(clip())},
+		Elements: []Element{element},
+	})
+}
+
+// textBlockElement wraps node text to the content box
+func (r *Renderer) textBlockElement(node *layout.Node, text NodeText, content Box, style Style) *TextElement {
+	opts := text.Block
+	if opts.Measurer == nil {
+		opts.Measurer = r.TextMeasurer()
+	}
+	if opts.Align == "" {
+		opts.Align = text.Align
+	}
+
+	ts := node.Style.TextStyle
+	if ts != nil {
+		if opts.Align == "" {
+			opts.Align = textAnchorFromAlign(ts.TextAlign)
+		}
+		if opts.WhiteSpace == "" {
+			opts.WhiteSpace = whiteSpaceFromLayout(ts.WhiteSpace)
+		}
+		if opts.LineHeight == 0 {
+			opts.LineHeight = lineHeightMultiplier(ts.LineHeight, FontSizePx(style))
+		}
+	}
+
+	if text.Overflow == OverflowHidden && ts != nil && ts.TextOverflow == layout.TextOverflowEllipsis && opts.MaxLines == 0 {
+		lineHeight := LayoutTextBlock("", 0, style, opts).LineHeight
+		if lineHeight > 0 {
+			opts.MaxLines = int(math.Max(1, math.Floor(content.Height/lineHeight)))
+		}
+	}
+
+	block := LayoutTextBlock(text.Content, content.Width, style, opts)
+	y := content.Y + verticalOffset(text.VerticalAlign, content.Height, block.Height)
+	return NewTextBlock(text.Content, content.X, y, content.Width, style, opts)
+}
+
+// textLayoutElement renders lines positioned by layout.LayoutText
+func textLayoutElement(tl *layout.TextLayout, content Box, style Style, valign VerticalAlign) *TextElement {
+	height := float64(len(tl.Lines)) * tl.LineHeight
+	top := content.Y + verticalOffset(valign, content.Height, height)
+
+	element := &TextElement{Style: style}
+	for _, line := range tl.Lines {
+		words := make([]string, 0, len(line.Boxes))
+		var ascent, descent float64
+		for _, b := range line.Boxes {
+			words = append(words, b.Text)
+			ascent = math.Max(ascent, b.Ascent)
+			descent = math.Max(descent, b.Descent)
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		x := content.X + line.OffsetX
+		baseline := top + line.OffsetY + (tl.LineHeight-ascent-descent)/2 + ascent
+		if len(element.Spans) == 0 {
+			element.X, element.Y = x, baseline
+		}
+
+		attrs := []Attr{{"x", formatFloat(x)}, {"y", formatFloat(baseline)}}
+		if line.SpaceAdjustment != 0 {
+			// Justified lines stretch their spaces
+			attrs = append(attrs, Attr{"word-spacing", formatFloat(line.SpaceAdjustment)})
+		}
+		element.Spans = append(element.Spans, &TSpanElement{Content: strings.Join(words, " "), Attrs: attrs})
+	}
+
+	if len(element.Spans) == 0 {
+		return nil
+	}
+	return element
+}
+
+// nodeTextStyle returns the base style for a node's text
+// It starts from the theme's text role and applies the node's font properties
+func (r *Renderer) nodeTextStyle(node *layout.Node) Style {
+	var style Style
+	if r.theme != nil {
+		style = r.theme.Style(RoleText)
+	}
+
+	if ts := node.Style.TextStyle; ts != nil {
+		if ts.FontSize > 0 {
+			style.FontSize = units.Px(ts.FontSize)
+		}
+		if ts.FontFamily != "" {
+			style.FontFamily = ts.FontFamily
+		}
+		if ts.FontWeight > 0 {
+			style.FontWeight = FontWeight(strconv.Itoa(int(ts.FontWeight)))
+		}
+	}
+	return style
+}
+
+// verticalOffset returns the offset of a block of the given height
+func verticalOffset(align VerticalAlign, available, height float64) float64 {
+	switch align {
+	case VerticalAlignMiddle:
+		return (available - height) / 2
+	case VerticalAlignBottom:
+		return available - height
+	}
+	return 0
+}
+
+// textAnchorFromAlign maps a layout text alignment to a text anchor
+func textAnchorFromAlign(align layout.TextAlign) TextAnchor {
+	switch align {
+	case layout.TextAlignCenter:
+		return TextAnchorMiddle
+	case layout.TextAlignRight:
+		return TextAnchorEnd
+	}
+	return TextAnchorStart
+}
+
+// whiteSpaceFromLayout maps a layout white-space mode
+func whiteSpaceFromLayout(ws layout.WhiteSpace) WhiteSpace {
+	switch ws {
+	case layout.WhiteSpaceNowrap:
+		return WhiteSpaceNoWrap
+	case layout.WhiteSpacePre:
+		return WhiteSpacePre
+	case layout.WhiteSpacePreWrap:
+		return WhiteSpacePreWrap
+	case layout.WhiteSpacePreLine:
+		return WhiteSpacePreLine
+	}
+	return WhiteSpaceNormal
+}
+
+// lineHeightMultiplier converts a layout line height to a multiple of the
+// font size; layout uses <=0 for normal, <10 for a multiplier and px otherwise
+func lineHeightMultiplier(lineHeight, fontSize float64) float64 {
+	switch {
+	case lineHeight <= 0:
+		return 1.2
+	case lineHeight < 10:
+		return lineHeight
+	}
+	return lineHeight / fontSize
+}
+
+// mergeStyle returns base with every non-zero field of over applied
+func mergeStyle(base, over Style) Style {
+	if over.Fill != "" {
+		base.Fill = over.Fill
+	}
+	if over.Stroke != "" {
+		base.Stroke = over.Stroke
+	}
+	if over.StrokeWidth != 0 {
+		base.StrokeWidth = over.StrokeWidth
+	}
+	if over.StrokeDashArray != "" {
+		base.StrokeDashArray = over.StrokeDashArray
+	}
+	if over.StrokeLinecap != "" {
+		base.StrokeLinecap = over.StrokeLinecap
+	}
+	if over.StrokeLinejoin != "" {
+		base.StrokeLinejoin = over.StrokeLinejoin
+	}
+	if over.Opacity != 0 {
+		base.Opacity = over.Opacity
+	}
+	if over.FillOpacity != 0 {
+		base.FillOpacity = over.FillOpacity
+	}
+	if over.StrokeOpacity != 0 {
+		base.StrokeOpacity = over.StrokeOpacity
+	}
+	if over.Class != "" {
+		base.Class = over.Class
+	}
+	if over.ClipPath != "" {
+		base.ClipPath = over.ClipPath
+	}
+	if over.TextAnchor != "" {
+		base.TextAnchor = over.TextAnchor
+	}
+	if over.DominantBaseline != "" {
+		base.DominantBaseline = over.DominantBaseline
+	}
+	if over.FontFamily != "" {
+		base.FontFamily = over.FontFamily
+	}
+	if over.FontSize.Value != 0 {
+		base.FontSize = over.FontSize
+	}
+	if over.FontWeight != "" {
+		base.FontWeight = over.FontWeight
+	}
+	if over.FontStyle != "" {
+		base.FontStyle = over.FontStyle
+	}
+	if over.Cursor != "" {
+		base.Cursor = over.Cursor
+	}
+	if over.PointerEvents != "" {
+		base.PointerEvents = over.PointerEvents
+	}
+	return base
+}
+
+// LayoutTextMetrics adapts a TextMeasurer for the layout engine, so text is
+// broken into lines with the same metrics used for rendering
+//
+//	layout.SetTextMetricsProvider(svg.LayoutTextMetrics(svg.DefaultTextMeasurer()))
+func LayoutTextMetrics(m TextMeasurer) layout.TextMetricsProvider {
+	return layoutMetrics{m}
+}
+
+type layoutMetrics struct {
+	measurer TextMeasurer
+}
+
+// Measure implements layout.TextMetricsProvider
+func (l layoutMetrics) Measure(text string, ts layout.TextStyle) (advance, ascent, descent float64) {
+	style := Style{FontFamily: ts.FontFamily}
+	if ts.FontSize > 0 {
+		style.FontSize = units.Px(ts.FontSize)
+	}
+	if ts.FontWeight > 0 {
+		style.FontWeight = FontWeight(strconv.Itoa(int(ts.FontWeight)))
+	}
+
+	m := l.measurer.Measure(text, style)
+	advance = m.Width
+	if ts.LetterSpacing != -1 && ts.LetterSpacing != 0 {
+		if n := len([]rune(text)); n > 1 {
+			advance += float64(n-1) * ts.LetterSpacing
+		}
+	}
+	return advance, m.Ascent, m.Descent
+}
@@ -0,0 +1,166 @@
+package svg
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestRenderNodeText(t *testing.T) {
+	padding := layout.Spacing{Top: layout.Px(10), Right: layout.Px(10), Bottom: layout.Px(10), Left: layout.Px(10)}
+	rect := layout.Rect{X: 0, Y: 0, Width: 200, Height: 100}
+
+	tests := []struct {
+		name     string
+		node     *layout.Node
+		textFunc func(node *layout.Node, depth int) (NodeText, bool)
+		boxFunc  func(node *layout.Node, depth int) (BoxStyle, bool)
+		expected []string
+		excluded []string
+	}{
+		{
+			name:     "positions text in the content box",
+			node:     &layout.Node{Text: "hello world", Rect: rect, Style: layout.Style{Padding: padding}},
+			expected: []string{`<tspan x="10.00" y="19.00">hello world</tspan>`},
+		},
+		{
+			name: "uses the layout text alignment",
+			node: &layout.Node{Text: "hi", Rect: rect, Style: layout.Style{
+				Padding:   padding,
+				TextStyle: &layout.TextStyle{TextAlign: layout.TextAlignCenter},
+			}},
+			expected: []string{`text-anchor="middle"`, `x="100.00"`},
+		},
+		{
+			name: "text hook with vertical alignment",
+			node: &layout.Node{Rect: rect, Style: layout.Style{Padding: padding}},
+			textFunc: func(node *layout.Node, depth int) (NodeText, bool) {
+				return NodeText{Content: "label", VerticalAlign: VerticalAlignMiddle, Style: Style{Fill: "#ff0000"}}, true
+			},
+			expected: []string{`<tspan x="10.00" y="53.00">label</tspan>`, `fill="#ff0000"`},
+		},
+		{
+			name: "hook returning false keeps node text",
+			node: &layout.Node{Text: "own", Rect: rect},
+			textFunc: func(node *layout.Node, depth int) (NodeText, bool) {
+				return NodeText{Content: "ignored"}, false
+			},
+			expected: []string{">own</tspan>"},
+			excluded: []string{"ignored"},
+		},
+		{
+			name: "overflow hidden clips and ellipsizes",
+			node: &layout.Node{Text: "aaa bbb ccc ddd", Rect: layout.Rect{Width: 50, Height: 40}, Style: layout.Style{
+				TextStyle: &layout.TextStyle{TextOverflow: layout.TextOverflowEllipsis},
+			}},
+			textFunc: func(node *layout.Node, depth int) (NodeText, bool) {
+				return NodeText{Overflow: OverflowHidden}, true
+			},
+			expected: []string{`clip-path="url(#clip-`, "<clipPath", ">aaa</tspan>", ">bbb…</tspan>"},
+			excluded: []string{"ccc"},
+		},
+		{
+			name: "overflow hidden clips to the content box",
+			node: &layout.Node{Text: "hello", Rect: rect, Style: layout.Style{Padding: padding}},
+			textFunc: func(node *layout.Node, depth int) (NodeText, bool) {
+				return NodeText{Overflow: OverflowHidden}, true
+			},
+			expected: []string{`<rect x="10.00" y="10.00" width="180.00" height="80.00"/></clipPath>`},
+		},
+		{
+			name: "box overflow hidden clips text and children with one clip path",
+			node: &layout.Node{Text: "hello", Rect: rect, Children: []*layout.Node{{Rect: layout.Rect{Width: 300, Height: 10}}}},
+			boxFunc: func(node *layout.Node, depth int) (BoxStyle, bool) {
+				return BoxStyle{Overflow: OverflowHidden, Radius: Corners{TopLeft: 8, TopRight: 8, BottomRight: 8, BottomLeft: 8}}, depth == 0
+			},
+			expected: []string{`<clipPath id="clip-1"><path`, `<g clip-path="url(#clip-1)"><text`, `<g clip-path="url(#clip-1)">` + "\n"},
+			excluded: []string{"clip-2"},
+		},
+		{
+			name: "text overflow overrides the box",
+			node: &layout.Node{Text: "hello", Rect: rect},
+			textFunc: func(node *layout.Node, depth int) (NodeText, bool) {
+				return NodeText{Overflow: OverflowVisible}, true
+			},
+			boxFunc: func(node *layout.Node, depth int) (BoxStyle, bool) {
+				return BoxStyle{Overflow: OverflowHidden}, true
+			},
+			excluded: []string{"<clipPath"},
+		},
+		{
+			name:     "nodes without text render no text",
+			node:     &layout.Node{Rect: rect},
+			excluded: []string{"<text"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.TextMeasurer = fixedMeasurer{}
+			opts.TextFunc = tt.textFunc
+			opts.BoxFunc = tt.boxFunc
+
+			svg := RenderToSVG(tt.node, opts)
+			for _, expected := range tt.expected {
+				if !strings.Contains(svg, expected) {
+					t.Errorf("Expected %q in:\n%s", expected, svg)
+				}
+			}
+			for _, excluded := range tt.excluded {
+				if strings.Contains(svg, excluded) {
+					t.Errorf("Did not expect %q in:\n%s", excluded, svg)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderNodeTextLayout(t *testing.T) {
+	node := &layout.Node{
+		Text:  "one two three four five six",
+		Style: layout.Style{Display: layout.DisplayInlineText},
+	}
+	size := layout.LayoutText(node, layout.Loose(80, math.Inf(1)), &layout.LayoutContext{RootFontSize: 16})
+	node.Rect = layout.Rect{X: 5, Y: 5, Width: size.Width, Height: size.Height}
+
+	if len(node.TextLayout.Lines) < 2 {
+		t.Fatalf("expected text to wrap, got %d lines", len(node.TextLayout.Lines))
+	}
+
+	svg := RenderToSVG(node, DefaultOptions())
+	if got := strings.Count(svg, "<tspan"); got != len(node.TextLayout.Lines) {
+		t.Errorf("Expected %d tspans, got %d:\n%s", len(node.TextLayout.Lines), got, svg)
+	}
+	if !strings.Contains(svg, ">one ") {
+		t.Errorf("Expected first line to start with the first word:\n%s", svg)
+	}
+}
+
+func TestBoxModel(t *testing.T) {
+	node := &layout.Node{
+		Rect: layout.Rect{X: 10, Y: 10, Width: 100, Height: 50},
+		Style: layout.Style{
+			Margin:  layout.Spacing{Top: layout.Px(5), Right: layout.Px(5), Bottom: layout.Px(5), Left: layout.Px(5)},
+			Border:  layout.Spacing{Top: layout.Px(1), Right: layout.Px(1), Bottom: layout.Px(1), Left: layout.Px(1)},
+			Padding: layout.Spacing{Top: layout.Px(4), Right: layout.Em(1), Bottom: layout.Px(4), Left: layout.Px(4)},
+		},
+	}
+
+	m := NewRenderer(DefaultOptions()).BoxModel(node)
+	if want := (Box{X: 5, Y: 5, Width: 110, Height: 60}); m.Margin != want {
+		t.Errorf("Margin = %+v, want %+v", m.Margin, want)
+	}
+	if want := (Box{X: 11, Y: 11, Width: 98, Height: 48}); m.Padding != want {
+		t.Errorf("Padding = %+v, want %+v", m.Padding, want)
+	}
+	if want := (Box{X: 15, Y: 15, Width: 78, Height: 40}); m.Content != want {
+		t.Errorf("Content = %+v, want %+v", m.Content, want)
+	}
+
+	if got := (Box{Width: 10, Height: 10}).Inset(Edges{Left: 8, Right: 8}); got.Width != 0 {
+		t.Errorf("Inset width = %v, want 0", got.Width)
+	}
+}
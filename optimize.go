@@ -0,0 +1,424 @@
+package svg
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// OptimizeOptions configures the SVG optimizer
+type OptimizeOptions struct {
+	// Precision is the number of decimal places kept for coordinates and lengths
+	// Opacity-like values always keep at least 2 decimals
+	// A negative value leaves numbers unchanged
+	Precision int
+
+	// ShortenPaths rewrites path data using the shortest encoding
+	// (relative vs absolute, implicit commands, H/V for axis-aligned lines)
+	ShortenPaths bool
+
+	// CollapseGroups removes empty <g> elements and unwraps groups without attributes
+	// Groups are not unwrapped in documents with a <style>, whose selectors may match them
+	CollapseGroups bool
+
+	// RemoveDefaults strips attributes that are set to their default value
+	// It does nothing in documents with a <style>, whose rules may override defaults
+	RemoveDefaults bool
+
+	// RemoveUnusedDefs drops definitions that are never referenced
+	RemoveUnusedDefs bool
+
+	// RemoveWhitespace drops indentation and collapses whitespace in <style>
+	RemoveWhitespace bool
+
+	// RemoveComments drops XML comments
+	RemoveComments bool
+}
+
+// DefaultOptimizeOptions returns options that enable every optimization
+func DefaultOptimizeOptions() OptimizeOptions {
+	return OptimizeOptions{
+		Precision:        2,
+		ShortenPaths:     true,
+		CollapseGroups:   true,
+		RemoveDefaults:   true,
+		RemoveUnusedDefs: true,
+		RemoveWhitespace: true,
+		RemoveComments:   true,
+	}
+}
+
+// Optimize returns a minified copy of a document
+//
+// The input document is not modified. The returned document contains
+// generic elements; Raw markup produced by the string helpers is parsed
+// so that it can be optimized as well.
+func Optimize(doc *Document, opts OptimizeOptions) *Document {
+	o := &optimizer{opts: opts, styled: hasStyleElement(doc.Children())}
+
+	out := &Document{
+		Name:   doc.Name,
+		Attrs:  o.attrs("svg", doc.Attributes()),
+		Prolog: o.outside(doc.Prolog),
+		Epilog: o.outside(doc.Epilog),
+	}
+
+	// Attributes of the root are inherited like those of any other element
+	elements := o.children("svg", doc.Children(), inheritedDefaults{styled: o.styled}.with(out.Attrs))
+	if opts.RemoveUnusedDefs {
+		elements = removeUnusedDefs(elements)
+	}
+	out.Add(elements...)
+
+	return out
+}
+
+// optimizer holds the state of an optimization pass
+type optimizer struct {
+	opts   OptimizeOptions
+	styled bool // the document has a <style>, whose rules may match any element
+}
+
+// inheritedDefaults tracks whether inherited presentation attributes still
+// hold their initial values, which decides if a default can be stripped
+type inheritedDefaults struct {
+	overridden map[string]bool
+	styled     bool // an ancestor has class or style, so CSS may affect inheritance
+}
+
+// outside returns the nodes written before or after the root element,
+// without comments if they are removed
+func (o *optimizer) outside(nodes []Element) []Element {
+	var out []Element
+	for _, node := range nodes {
+		if _, ok := node.(Comment); ok && o.opts.RemoveComments {
+			continue
+		}
+		out = append(out, node)
+	}
+	return out
+}
+
+// element optimizes a single node; it returns nil if the node is dropped
+func (o *optimizer) element(parent string, e Element, inherited inheritedDefaults) []Element {
+	switch n := e.(type) {
+	case CharData:
+		if o.opts.RemoveWhitespace && !preservesWhitespace(parent) {
+			if strings.TrimSpace(string(n)) == "" {
+				return nil
+			}
+			if parent == "style" {
+				return []Element{CharData(collapseWhitespace(string(n)))}
+			}
+		}
+		return []Element{n}
+
+	case Comment:
+		if o.opts.RemoveComments {
+			return nil
+		}
+		return []Element{n}
+
+	case Raw:
+		// Markup from string helpers is parsed so it can be optimized too
+		fragment, err := parseFragment(string(n))
+		if err != nil {
+			return []Element{n}
+		}
+		return o.children(parent, fragment, inherited)
+	}
+
+	tag := e.Tag()
+	attrs := o.attrs(tag, e.Attributes())
+	if o.opts.RemoveDefaults && !o.styled {
+		attrs = removeDefaults(tag, attrs, inherited)
+	}
+
+	children := o.children(tag, e.Children(), inherited.with(attrs))
+
+	if tag == "g" && o.opts.CollapseGroups {
+		if len(children) == 0 && attrValue(attrs, "id") == "" {
+			return nil
+		}
+		if len(attrs) == 0 && !o.styled {
+			return children
+		}
+	}
+
+	return []Element{&GenericElement{Name: tag, Attrs: attrs, Elements: children}}
+}
+
+// children optimizes a list of child nodes
+func (o *optimizer) children(parent string, nodes []Element, inherited inheritedDefaults) []Element {
+	var out []Element
+	for _, child := range nodes {
+		out = append(out, o.element(parent, child, inherited)...)
+	}
+	return out
+}
+
+// attrs optimizes attribute values
+func (o *optimizer) attrs(tag string, attrs []Attr) []Attr {
+	out := make([]Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		value := attr.Value
+		switch {
+		case attr.Name == "d" && o.opts.ShortenPaths:
+			if shortened, err := shortenPathData(value, o.opts.Precision); err == nil {
+				value = shortened
+			}
+		case numericAttributes[attr.Name] && o.opts.Precision >= 0:
+			precision := o.opts.Precision
+			if unitIntervalAttributes[attr.Name] && precision < 2 {
+				precision = 2
+			}
+			value = roundNumbers(value, precision)
+		}
+		out = append(out, Attr{Name: attr.Name, Value: value})
+	}
+	return out
+}
+
+// with returns the inherited state for the children of an element with attrs
+func (in inheritedDefaults) with(attrs []Attr) inheritedDefaults {
+	out := inheritedDefaults{styled: in.styled, overridden: in.overridden}
+	copied := false
+	for _, attr := range attrs {
+		if attr.Name == "class" || attr.Name == "style" {
+			out.styled = true
+		}
+		if _, ok := inheritedAttributeDefaults[attr.Name]; ok {
+			if !copied {
+				out.overridden = make(map[string]bool, len(in.overridden)+1)
+				for k, v := range in.overridden {
+					out.overridden[k] = v
+				}
+				copied = true
+			}
+			out.overridden[attr.Name] = true
+		}
+	}
+	return out
+}
+
+// numericAttributes lists attributes whose numbers are rounded to the precision
+// Transforms are not rounded, as rounding their coefficients changes the geometry
+var numericAttributes = map[string]bool{
+	"x": true, "y": true, "width": true, "height": true,
+	"rx": true, "ry": true, "cx": true, "cy": true, "r": true,
+	"fx": true, "fy": true, "fr": true,
+	"x1": true, "y1": true, "x2": true, "y2": true,
+	"dx": true, "dy": true,
+	"refX": true, "refY": true, "markerWidth": true, "markerHeight": true,
+	"stroke-width": true, "stroke-dasharray": true, "stroke-dashoffset": true,
+	"opacity": true, "fill-opacity": true, "stroke-opacity": true, "stop-opacity": true,
+	"offset": true, "font-size": true, "letter-spacing": true, "word-spacing": true,
+	"points": true, "viewBox": true,
+	"d": true, "startOffset": true,
+}
+
+// unitIntervalAttributes lists attributes in [0,1] that need extra precision
+var unitIntervalAttributes = map[string]bool{
+	"opacity": true, "fill-opacity": true, "stroke-opacity": true, "stop-opacity": true,
+	"offset": true,
+}
+
+// inheritedAttributeDefaults lists inherited presentation attributes and their initial values
+var inheritedAttributeDefaults = map[string]string{
+	"fill-opacity":     "1",
+	"stroke-opacity":   "1",
+	"stroke-width":     "1",
+	"stroke-linecap":   "butt",
+	"stroke-linejoin":  "miter",
+	"stroke-dasharray": "none",
+	"fill-rule":        "nonzero",
+	"font-style":       "normal",
+	"font-weight":      "normal",
+	"text-anchor":      "start",
+	"visibility":       "visible",
+}
+
+// elementAttributeDefaults lists non-inherited attributes and their defaults per element
+var elementAttributeDefaults = map[string]map[string]string{
+	"*":       {"opacity": "1"},
+	"rect":    {"x": "0", "y": "0"},
+	"circle":  {"cx": "0", "cy": "0"},
+	"ellipse": {"cx": "0", "cy": "0"},
+	"line":    {"x1": "0", "y1": "0", "x2": "0", "y2": "0"},
+	"text":    {"x": "0", "y": "0"},
+	"stop":    {"stop-opacity": "1"},
+}
+
+// removeDefaults strips attributes whose value is the default
+func removeDefaults(tag string, attrs []Attr, inherited inheritedDefaults) []Attr {
+	out := attrs[:0:0]
+	for _, attr := range attrs {
+		if isDefaultAttr(tag, attr, inherited) || isDefaultRadius(tag, attr, attrs) {
+			continue
+		}
+		out = append(out, attr)
+	}
+	return out
+}
+
+func isDefaultAttr(tag string, attr Attr, inherited inheritedDefaults) bool {
+	if def, ok := inheritedAttributeDefaults[attr.Name]; ok {
+		if inherited.styled || inherited.overridden[attr.Name] {
+			return false
+		}
+		return sameValue(attr.Value, def)
+	}
+	if def, ok := elementAttributeDefaults[tag][attr.Name]; ok {
+		return sameValue(attr.Value, def)
+	}
+	if def, ok := elementAttributeDefaults["*"][attr.Name]; ok {
+		return sameValue(attr.Value, def)
+	}
+	return false
+}
+
+// isDefaultRadius reports whether a rect corner radius can be dropped
+// A missing rx or ry takes the value of the other, so a zero radius is only
+// a default when the other one is zero or missing too
+func isDefaultRadius(tag string, attr Attr, attrs []Attr) bool {
+	if tag != "rect" || (attr.Name != "rx" && attr.Name != "ry") || !sameValue(attr.Value, "0") {
+		return false
+	}
+	other := "ry"
+	if attr.Name == "ry" {
+		other = "rx"
+	}
+	value := attrValue(attrs, other)
+	return value == "" || sameValue(value, "0")
+}
+
+// hasStyleElement reports whether a <style> element occurs in the elements,
+// including inside Raw markup
+func hasStyleElement(elements []Element) bool {
+	for _, e := range elements {
+		if raw, ok := e.(Raw); ok {
+			if strings.Contains(string(raw), "<style") {
+				return true
+			}
+			continue
+		}
+		if localName(e.Tag()) == "style" || hasStyleElement(e.Children()) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue compares attribute values, numerically when both are numbers
+func sameValue(a, b string) bool {
+	if a == b {
+		return true
+	}
+	fa, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	return errA == nil && errB == nil && fa == fb
+}
+
+// removeUnusedDefs drops <defs> children with ids that are never referenced
+// It repeats until stable, since removed defs may reference other defs
+func removeUnusedDefs(elements []Element) []Element {
+	for {
+		refs := make(map[string]bool)
+		for _, e := range elements {
+			collectRefs(e, refs)
+		}
+
+		removed := false
+		var prune func(nodes []Element, inDefs bool) []Element
+		prune = func(nodes []Element, inDefs bool) []Element {
+			out := nodes[:0:0]
+			for _, n := range nodes {
+				g, ok := n.(*GenericElement)
+				if !ok {
+					out = append(out, n)
+					continue
+				}
+				if inDefs {
+					if id := elementID(g); id != "" && !refs[id] {
+						removed = true
+						continue
+					}
+				}
+				g.Elements = prune(g.Elements, g.Name == "defs")
+				if g.Name == "defs" && len(g.Elements) == 0 {
+					continue
+				}
+				out = append(out, g)
+			}
+			return out
+		}
+		elements = prune(elements, false)
+
+		if !removed {
+			return elements
+		}
+	}
+}
+
+// preservesWhitespace reports whether whitespace is significant inside an element
+func preservesWhitespace(tag string) bool {
+	switch tag {
+	case "text", "tspan", "textPath", "title", "desc":
+		return true
+	}
+	return false
+}
+
+// collapseWhitespace collapses runs of whitespace to a single space
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+var numberPattern = regexp.MustCompile(`[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+
+// roundNumbers rounds every number in an attribute value to precision decimals
+func roundNumbers(value string, precision int) string {
+	return numberPattern.ReplaceAllStringFunc(value, func(s string) string {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return s
+		}
+		return compactNumber(v, precision)
+	})
+}
+
+// compactNumber formats a number with at most precision decimals,
+// trimming trailing zeros and the leading zero of fractions
+// A negative precision keeps the shortest exact representation
+func compactNumber(v float64, precision int) string {
+	var s string
+	if precision < 0 {
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	} else {
+		s = strconv.FormatFloat(roundTo(v, precision), 'f', precision, 64)
+		if strings.Contains(s, ".") {
+			s = strings.TrimRight(s, "0")
+			s = strings.TrimSuffix(s, ".")
+		}
+	}
+
+	switch {
+	case s == "-0":
+		return "0"
+	case strings.HasPrefix(s, "0."):
+		return s[1:]
+	case strings.HasPrefix(s, "-0."):
+		return "-" + s[2:]
+	}
+	return s
+}
+
+// roundTo rounds v to precision decimal places
+func roundTo(v float64, precision int) float64 {
+	if precision < 0 {
+		return v
+	}
+	scale := math.Pow(10, float64(precision))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,187 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShortenPathData(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Axis-aligned lines", "M 10.00 10.00 L 90.00 10.00 L 90.00 90.00 L 10.00 90.00 Z", "M10 10H90V90H10Z"},
+		{"Relative curves", "M 10.00 80.00 C 40.00 10.00, 65.00 10.00, 95.00 80.00 S 150.00 150.00, 180.00 80.00", "M10 80c30-70 55-70 85 0s55 70 85 0"},
+		{"Implicit commands", "M 0 0 L 10 50 L 5 20", "M0 0L10 50 5 20"},
+		{"Leading zeros", "M 0.5 0.5 L 0.25 0.75", "M.5.5L.25.75"},
+		{"Precision", "M 1.23456 2.34567", "M1.23 2.35"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := shortenPathData(tt.input, 2)
+			if err != nil {
+				t.Fatalf("shortenPathData failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParsePathDataRelative(t *testing.T) {
+	segments, err := ParsePathData("m10 10 5 5h10v-5a2 2 0 015 5z")
+	if err != nil {
+		t.Fatalf("ParsePathData failed: %v", err)
+	}
+
+	expected := []PathSegment{
+		{'M', []float64{10, 10}},
+		{'L', []float64{15, 15}},
+		{'H', []float64{25}},
+		{'V', []float64{10}},
+		{'A', []float64{2, 2, 0, 0, 1, 30, 15}},
+		{'Z', []float64{}},
+	}
+	if len(segments) != len(expected) {
+		t.Fatalf("Expected %d segments, got %d: %v", len(expected), len(segments), segments)
+	}
+	for i, seg := range segments {
+		if seg.Command != expected[i].Command {
+			t.Errorf("Segment %d: expected %c, got %c", i, expected[i].Command, seg.Command)
+		}
+		for j, v := range seg.Args {
+			if v != expected[i].Args[j] {
+				t.Errorf("Segment %d arg %d: expected %v, got %v", i, j, expected[i].Args[j], v)
+			}
+		}
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	doc := NewDocument(100, 100)
+	if _, err := doc.AddDef(&LinearGradientElement{Def: LinearGradientDef{ID: "unused"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doc.AddDef(&LinearGradientElement{Def: LinearGradientDef{ID: "used", X1: "0%"}}); err != nil {
+		t.Fatal(err)
+	}
+	doc.Add(
+		Raw("\n  "),
+		&GroupElement{Elements: []Element{
+			&RectElement{X: 0, Y: 0, Width: 10.004, Height: 20, Style: Style{Fill: GradientURL("used")}},
+			Comment(" bar "),
+		}},
+		&GroupElement{Transform: "translate(1.234,5.678)"},
+		&PathElement{D: "M 0.00 0.00 L 10.00 0.00", Attrs: []Attr{{"stroke-width", "1.00"}}},
+	)
+
+	result := Optimize(doc, DefaultOptimizeOptions()).String()
+
+	expected := `<svg width="100" height="100" viewBox="0 0 100 100" xmlns="http://www.w3.org/2000/svg">` +
+		`<defs><linearGradient id="used" x1="0%"/></defs>` +
+		`<rect width="10" height="20" fill="url(#used)"/>` +
+		`<path d="M0 0H10"/>` +
+		`</svg>`
+	if result != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, result)
+	}
+}
+
+func TestOptimizeKeepsInheritedOverrides(t *testing.T) {
+	doc := NewDocument(10, 10)
+	doc.Add(&GroupElement{
+		Attrs: []Attr{{"stroke-width", "2"}},
+		Elements: []Element{
+			&LineElement{X2: 10, Attrs: []Attr{{"stroke-width", "1"}}},
+		},
+	})
+
+	result := Optimize(doc, DefaultOptimizeOptions()).String()
+	if !strings.Contains(result, `<line x2="10" stroke-width="1"/>`) {
+		t.Errorf("Expected stroke-width override to be kept, got:\n%s", result)
+	}
+}
+
+func TestOptimizeRemoveDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "root attributes are inherited",
+			input:    `<svg stroke-width="3"><rect width="5" height="5" stroke-width="1"/></svg>`,
+			expected: `<rect width="5" height="5" stroke-width="1"/>`,
+		},
+		{
+			name:     "zero rx is kept when ry is set",
+			input:    `<svg><rect width="5" height="5" rx="0" ry="2"/></svg>`,
+			expected: `<rect width="5" height="5" rx="0" ry="2"/>`,
+		},
+		{
+			name:     "zero radii are dropped together",
+			input:    `<svg><rect width="5" height="5" rx="0" ry="0"/></svg>`,
+			expected: `<rect width="5" height="5"/>`,
+		},
+		{
+			name:     "stylesheets keep defaults and groups",
+			input:    `<svg><style>g { stroke-width: 3; }</style><g><rect x="0" width="5" height="5" stroke-width="1"/></g></svg>`,
+			expected: `<style>g { stroke-width: 3; }</style><g><rect x="0" width="5" height="5" stroke-width="1"/></g>`,
+		},
+		{
+			name:     "definitions used from CSS are kept",
+			input:    `<svg><defs><linearGradient id="g"/></defs><style>rect { fill: url(#g); }</style><rect width="5" height="5"/></svg>`,
+			expected: `<linearGradient id="g"/>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			result := Optimize(doc, DefaultOptimizeOptions()).String()
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Expected %q in:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestOptimizeKeepsTransforms(t *testing.T) {
+	input := `<svg><g transform="matrix(0.7071 0.7071 -0.7071 0.7071 0 0)"><rect width="5" height="5"/></g>` +
+		`<linearGradient id="g" gradientTransform="rotate(33.333)"/><rect width="5" height="5" fill="url(#g)"/></svg>`
+
+	for _, precision := range []int{0, 2} {
+		doc, err := ParseString(input)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		opts := DefaultOptimizeOptions()
+		opts.Precision = precision
+
+		result := Optimize(doc, opts).String()
+		for _, expected := range []string{`transform="matrix(0.7071 0.7071 -0.7071 0.7071 0 0)"`, `gradientTransform="rotate(33.333)"`} {
+			if !strings.Contains(result, expected) {
+				t.Errorf("Precision %d: expected %q in:\n%s", precision, expected, result)
+			}
+		}
+	}
+}
+
+func TestOptimizeShrinksRenderedOutput(t *testing.T) {
+	input := RenderToSVG(nil, DefaultOptions())
+	doc, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	result := Optimize(doc, DefaultOptimizeOptions()).String()
+	if len(result) >= len(input) {
+		t.Errorf("Expected optimized output (%d bytes) to be smaller than input (%d bytes)", len(result), len(input))
+	}
+}
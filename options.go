@@ -1,5 +1,7 @@
 package svg
 
+import "github.com/SCKelemen/layout"
+
 // Options configures SVG rendering behavior
 type Options struct {
 	// Width of the output SVG
@@ -24,15 +26,102 @@ type Options struct {
 	// PreserveAspectRatio sets the preserveAspectRatio attribute
 	PreserveAspectRatio string
 
+	// Title is the accessible name of the SVG, written as its <title>
+	// The root gets role="img" and aria-labelledby pointing at it
+	Title string
+
+	// Description is written as <desc> and linked with aria-describedby
+	Description string
+
+	// DataTable adds an invisible, accessible table of the data shown,
+	// so screen readers can read a chart's values (optional)
+	DataTable *DataTable
+
+	// AccessibleNameFunc names nodes for assistive technology (optional)
+	// Nodes with a name become role="img", or role="group" if they have children
+	AccessibleNameFunc func(node *layout.Node, depth int) string
+
 	// BackgroundColor sets a background rectangle (optional)
+	// If empty and a Theme is set, the theme background is used
 	BackgroundColor string
 
+	// Theme supplies design tokens for the output (optional)
+	// Its stylesheet is written before StyleSheet, and nodes are styled
+	// with Theme.DepthStyle unless StyleFunc is set
+	Theme *Theme
+
+	// DarkTheme pairs Theme with a dark variant (optional)
+	// Colors are then emitted as CSS custom properties that switch under
+	// @media (prefers-color-scheme: dark); Theme defaults to LightTheme
+	DarkTheme *Theme
+
+	// IDPrefix is prepended to generated IDs such as clip paths and gradients
+	IDPrefix string
+
+	// UniqueIDs adds a random token to generated IDs, so several SVGs can be
+	// inlined into one HTML page without clashes; output is then not stable
+	UniqueIDs bool
+
+	// DeduplicateStyles emits one generated CSS class per distinct node style
+	// instead of repeating presentation attributes on every element
+	DeduplicateStyles bool
+
+	// StyleClassPrefix is the prefix of generated style classes (default "s-")
+	StyleClassPrefix string
+
+	// Debug draws an overlay over the output showing each node's margin,
+	// border, padding and content areas, its geometry, and baseline and gap
+	// guides, in the colors of browser devtools
+	Debug bool
+
+	// Cull skips subtrees that lie entirely outside the viewBox
+	// Bounds come from layout rects, so anything a node draws outside its
+	// subtree's rects, such as shadows, is culled with it; nodes with a
+	// transform and their descendants are never culled
+	Cull bool
+
+	// MinNodeSize collapses subtrees smaller than this many output pixels
+	// in both directions into aggregate rectangles, one per pixel cell
+	// covered, filled with the node's color (optional)
+	// Nodes under a transform are never collapsed
+	MinNodeSize float64
+
+	// TextMeasurer measures text for layout-aware labels (optional)
+	// If nil, DefaultTextMeasurer is used
+	TextMeasurer TextMeasurer
+
+	// TextFunc supplies text to draw inside a node's content box (optional)
+	// Nodes with layout text (node.Text) are drawn even without it; return
+	// false to use the node's own text and text style
+	TextFunc func(node *layout.Node, depth int) (NodeText, bool)
+
+	// BoxFunc decorates a node's box with borders, radii, backgrounds and
+	// shadows (optional); return false to draw a plain rectangle
+	// Border widths come from the node's Style.Border unless overridden
+	BoxFunc func(node *layout.Node, depth int) (BoxStyle, bool)
+
+	// NodeAttrsFunc adds ids, data attributes, links and tooltips to the
+	// group of a node, so front-end code can find and hook into it (optional)
+	NodeAttrsFunc func(node *layout.Node, depth int) NodeAttributes
+
+	// NodeIDs marks each node's group with its position in the tree as
+	// data-node, e.g. data-node="0.2" for the third child of the root
+	NodeIDs bool
+
+	// NodeRenderers extend node rendering with Before, Render, Children and
+	// After hooks, called in order for every node
+	NodeRenderers []NodeRenderer
+
 	// StyleFunc allows custom styling per node
 	// Called for each node with the node and its depth in the tree
+	//
+	// Deprecated: set RenderContext.Style in a NodeRenderer Before hook
 	StyleFunc func(node interface{}, depth int) Style
 
 	// RenderFunc allows custom rendering per node type
 	// If it returns non-empty string, that's used instead of default rendering
+	//
+	// Deprecated: use a NodeRenderer Render hook
 	RenderFunc func(node interface{}, depth int) string
 }
 
@@ -0,0 +1,156 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Page sizes in CSS pixels, at 96 per inch
+const (
+	A4Width      = 793.7
+	A4Height     = 1122.52
+	LetterWidth  = 816.0
+	LetterHeight = 1056.0
+)
+
+// PageOptions configures how a tall layout tree is split into pages
+type PageOptions struct {
+	// Options renders each page; Width and Height are the page size
+	Options Options
+
+	// Header is drawn at the top of every page (optional)
+	// It is laid out separately and drawn at its own position
+	Header *layout.Node
+
+	// Footer is drawn at the bottom of every page (optional)
+	// It is laid out separately and moved to the bottom of the page
+	Footer *layout.Node
+
+	// Unbreakable marks nodes that must not be split across pages (optional)
+	// A page ends above such a node instead, unless the node is taller than
+	// a page
+	Unbreakable func(node *layout.Node) bool
+}
+
+// DefaultPageOptions returns options for A4 pages
+func DefaultPageOptions() PageOptions {
+	opts := DefaultOptions()
+	opts.Width = A4Width
+	opts.Height = A4Height
+	return PageOptions{Options: opts}
+}
+
+// RenderPages splits a layout tree into pages and renders each as an SVG
+//
+// The tree is cut at page height, between the header and footer, moving a
+// cut up above any unbreakable node it would go through. Each page shows
+// its slice of the tree, clipped to the space between header and footer.
+// Use ExportPDF to combine the pages into one PDF.
+func RenderPages(root *layout.Node, opts PageOptions) ([]string, error) {
+	var headerHeight, footerHeight float64
+	if opts.Header != nil {
+		headerHeight = opts.Header.Rect.Height
+	}
+	if opts.Footer != nil {
+		footerHeight = opts.Footer.Rect.Height
+	}
+	bodyHeight := opts.Options.Height - headerHeight - footerHeight
+	if bodyHeight <= 0 {
+		return nil, fmt.Errorf("header and footer leave no room on a page of height %s", formatDimension(opts.Options.Height))
+	}
+
+	var pages []string
+	for _, page := range pageBreaks(root, bodyHeight, opts.Unbreakable) {
+		r := NewRenderer(opts.Options)
+		pages = append(pages, r.document(func(nw *nodeWriter) {
+			r.renderPage(nw, root, page, headerHeight, opts)
+		}))
+	}
+	return pages, nil
+}
+
+// pageSlice is the range of the tree, in its coordinates, shown on a page
+type pageSlice struct {
+	top, bottom float64
+}
+
+// Indices of the top-level nodes of a page, as used in node paths
+const (
+	pageBodyIndex = iota
+	pageHeaderIndex
+	pageFooterIndex
+)
+
+// renderPage renders the header, the slice of the tree and the footer
+// The tree keeps the node paths it has with Render; the header and footer
+// follow it as the second and third top-level nodes
+func (r *Renderer) renderPage(nw *nodeWriter, root *layout.Node, page pageSlice, headerHeight float64, opts PageOptions) {
+	width := opts.Options.Width
+	if opts.Header != nil {
+		r.renderNode(nw, opts.Header, pageHeaderIndex)
+	}
+
+	// Move the slice below the header and clip it to its range
+	clip := r.clips.AddRect(0, page.top, width, page.bottom-page.top)
+	transform := fmt.Sprintf("translate(0, %s)", formatDimension(headerHeight-page.top))
+	nw.write("<g")
+	nw.write(formatAttrs([]Attr{
+		{"transform", transform},
+		{"clip-path", URL(clip)},
+	}))
+	nw.write(">\n")
+
+	// Skip the parts of the tree on other pages
+	cull := r.cull
+	r.cull = pageCulling(r.options, Box{Y: page.top, Width: width, Height: page.bottom - page.top})
+	r.renderNodeIn(nw, root, pageBodyIndex, transform)
+	r.cull = cull
+
+	nw.write("</g>\n")
+
+	if footer := opts.Footer; footer != nil {
+		y := opts.Options.Height - footer.Rect.Height - footer.Rect.Y
+		transform := fmt.Sprintf("translate(0, %s)", formatDimension(y))
+		nw.write("<g")
+		nw.write(formatAttrs([]Attr{{"transform", transform}}))
+		nw.write(">\n")
+		r.renderNodeIn(nw, footer, pageFooterIndex, transform)
+		nw.write("</g>\n")
+	}
+}
+
+// pageBreaks splits the height of a tree into slices of at most height,
+// ending each slice above any unbreakable node it would cut through
+func pageBreaks(root *layout.Node, height float64, unbreakable func(*layout.Node) bool) []pageSlice {
+	var keep []layout.Rect
+	top, bottom := root.Rect.Y, root.Rect.Y+root.Rect.Height
+	var walk func(node *layout.Node)
+	walk = func(node *layout.Node) {
+		bottom = math.Max(bottom, node.Rect.Y+node.Rect.Height)
+		if unbreakable != nil && unbreakable(node) {
+			keep = append(keep, node.Rect)
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	var pages []pageSlice
+	for start := top; start < bottom || len(pages) == 0; {
+		end := start + height
+		for moved := true; moved && end < bottom; {
+			moved = false
+			for _, rect := range keep {
+				if rect.Y > start && rect.Y < end && rect.Y+rect.Height > end {
+					end, moved = rect.Y, true
+				}
+			}
+		}
+		pages = append(pages, pageSlice{top: start, bottom: end})
+		start = end
+	}
+	return pages
+}
@@ -0,0 +1,137 @@
+package svg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+// report returns a column of 100px rows
+func report(rows int) *layout.Node {
+	root := &layout.Node{Rect: layout.Rect{Width: 200, Height: float64(rows) * 100}}
+	for i := 0; i < rows; i++ {
+		root.Children = append(root.Children, &layout.Node{Rect: layout.Rect{Y: float64(i) * 100, Width: 200, Height: 100}})
+	}
+	return root
+}
+
+func TestPageBreaks(t *testing.T) {
+	root := report(5)
+	unbreakable := func(n *layout.Node) bool { return n != root }
+
+	tests := []struct {
+		name        string
+		height      float64
+		unbreakable func(*layout.Node) bool
+		expected    []pageSlice
+	}{
+		{"cut at page height", 250, nil, []pageSlice{{0, 250}, {250, 500}}},
+		{"rows are kept whole", 250, unbreakable, []pageSlice{{0, 200}, {200, 400}, {400, 650}}},
+		{"rows taller than a page are cut", 50, unbreakable, []pageSlice{{0, 50}, {50, 100}, {100, 150}, {150, 200}, {200, 250}, {250, 300}, {300, 350}, {350, 400}, {400, 450}, {450, 500}}},
+		{"one page", 1000, unbreakable, []pageSlice{{0, 1000}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pageBreaks(root, tt.height, tt.unbreakable)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderPages(t *testing.T) {
+	root := report(5)
+	opts := PageOptions{
+		Options:     WithSize(200, 300),
+		Header:      &layout.Node{Rect: layout.Rect{Width: 200, Height: 20}},
+		Footer:      &layout.Node{Rect: layout.Rect{Width: 200, Height: 30}},
+		Unbreakable: func(n *layout.Node) bool { return n != root },
+	}
+
+	opts.Options.NodeIDs = true
+
+	pages, err := RenderPages(root, opts)
+	if err != nil {
+		t.Fatalf("RenderPages failed: %v", err)
+	}
+	// 250px between header and footer fits two rows per page
+	if len(pages) != 3 {
+		t.Fatalf("Expected 3 pages, got %d", len(pages))
+	}
+
+	for i, page := range pages {
+		for _, expected := range []string{
+			`<svg width="200" height="300"`,
+			`<rect x="0.00" y="0.00" width="200.00" height="20.00"`, // Header
+			`<g transform="translate(0, 270)">`,                     // Footer
+			`clip-path="url(#clip-1)"`,
+			`data-node="0"`, // Body
+			`data-node="1"`, // Header
+			`data-node="2"`, // Footer
+		} {
+			if !strings.Contains(page, expected) {
+				t.Errorf("Page %d: expected %q in:\n%s", i+1, expected, page)
+			}
+		}
+	}
+
+	// Each page only draws its own rows, moved below the header
+	second := pages[1]
+	for _, expected := range []string{`<g transform="translate(0, -180)"`, `y="200.00"`, `y="300.00"`} {
+		if !strings.Contains(second, expected) {
+			t.Errorf("Expected %q on page 2:\n%s", expected, second)
+		}
+	}
+	if strings.Contains(second, `y="100.00"`) || strings.Contains(second, `y="400.00"`) {
+		t.Errorf("Expected rows of other pages to be culled:\n%s", second)
+	}
+}
+
+func TestRenderPagesNoRoom(t *testing.T) {
+	opts := PageOptions{
+		Options: WithSize(200, 40),
+		Header:  &layout.Node{Rect: layout.Rect{Height: 20}},
+		Footer:  &layout.Node{Rect: layout.Rect{Height: 20}},
+	}
+	if _, err := RenderPages(report(1), opts); err == nil {
+		t.Error("Expected an error when header and footer fill the page")
+	}
+}
+
+func TestExportPDF(t *testing.T) {
+	pages, err := RenderPages(report(5), PageOptions{Options: WithSize(200, 300)})
+	if err != nil {
+		t.Fatalf("RenderPages failed: %v", err)
+	}
+
+	// The page size does not depend on the resolution
+	opts := DefaultExportOptions()
+	opts.DPI = 300
+	pdf, err := ExportPDF(pages, opts)
+	if err != nil {
+		t.Fatalf("ExportPDF failed: %v", err)
+	}
+
+	for _, expected := range []string{"%PDF-1.4", "/Type /Pages", "/Count 2", "/MediaBox [0 0 150 225]", "/Filter /DCTDecode", "%%EOF"} {
+		if !bytes.Contains(pdf, []byte(expected)) {
+			t.Errorf("Expected %q in PDF", expected)
+		}
+	}
+	if n := bytes.Count(pdf, []byte("/Type /Page ")); n != 2 {
+		t.Errorf("Expected 2 pages, got %d", n)
+	}
+
+	opts.Width, opts.Height = 400, 600
+	if pdf, err = ExportPDF(pages, opts); err != nil || !bytes.Contains(pdf, []byte("/MediaBox [0 0 150 225]")) {
+		t.Errorf("Expected the image size not to change the page size (err %v)", err)
+	}
+}
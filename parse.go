@@ -0,0 +1,144 @@
+package svg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Parse reads an SVG document into the element model
+//
+// Parsing is lossless for everything the element model can represent:
+// namespace prefixes and declarations, unknown elements and attributes,
+// mixed text content and comments are all preserved, so writing the
+// returned document reproduces the input markup (whitespace outside the
+// root element aside). Elements are returned
+// as *GenericElement, text as CharData and comments as Comment.
+// Processing instructions, directives and comments before the root
+// element are kept in Document.Prolog, comments and processing
+// instructions after it in Document.Epilog, and the root element name,
+// with its prefix, in Document.Name. Processing instructions inside the
+// root element are dropped.
+func Parse(r io.Reader) (*Document, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = true
+
+	doc := &Document{}
+	var root *GenericElement
+	var stack []*GenericElement
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SVG: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			elem := &GenericElement{
+				Name:  qualifiedName(t.Name),
+				Attrs: make([]Attr, len(t.Attr)),
+			}
+			for i, attr := range t.Attr {
+				elem.Attrs[i] = Attr{Name: qualifiedName(attr.Name), Value: attr.Value}
+			}
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Elements = append(parent.Elements, elem)
+			} else if root == nil {
+				root = elem
+			} else {
+				return nil, fmt.Errorf("failed to parse SVG: multiple root elements")
+			}
+			stack = append(stack, elem)
+
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1].Name != qualifiedName(t.Name) {
+				return nil, fmt.Errorf("failed to parse SVG: unexpected </%s>", qualifiedName(t.Name))
+			}
+			stack = stack[:len(stack)-1]
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				appendText(stack[len(stack)-1], string(t))
+			}
+
+		case xml.Comment:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Elements = append(parent.Elements, Comment(t))
+			} else if root == nil {
+				doc.Prolog = append(doc.Prolog, Comment(t))
+			} else {
+				doc.Epilog = append(doc.Epilog, Comment(t))
+			}
+
+		case xml.ProcInst:
+			pi := Raw(fmt.Sprintf("<?%s %s?>", t.Target, t.Inst))
+			if root == nil {
+				doc.Prolog = append(doc.Prolog, pi)
+			} else if len(stack) == 0 {
+				doc.Epilog = append(doc.Epilog, pi)
+			}
+
+		case xml.Directive:
+			if root == nil {
+				doc.Prolog = append(doc.Prolog, Raw(fmt.Sprintf("<!%s>", t)))
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("no SVG root element found")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("failed to parse SVG: unclosed <%s>", stack[len(stack)-1].Name)
+	}
+	if root.Name != "svg" && !strings.HasSuffix(root.Name, ":svg") {
+		return nil, fmt.Errorf("root element is <%s>, not <svg>", root.Name)
+	}
+
+	doc.Name = root.Name
+	doc.Attrs = root.Attrs
+	doc.Add(root.Elements...)
+
+	return doc, nil
+}
+
+// ParseString parses SVG markup from a string
+func ParseString(s string) (*Document, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// qualifiedName returns an XML name with its namespace prefix, as written in the source
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// appendText adds character data to an element, merging adjacent text nodes
+func appendText(elem *GenericElement, text string) {
+	if n := len(elem.Elements); n > 0 {
+		if prev, ok := elem.Elements[n-1].(CharData); ok {
+			elem.Elements[n-1] = prev + CharData(text)
+			return
+		}
+	}
+	elem.Elements = append(elem.Elements, CharData(text))
+}
+
+// parseFragment parses a sequence of SVG nodes that has no single root element
+func parseFragment(markup string) ([]Element, error) {
+	doc, err := ParseString("<svg>" + markup + "</svg>")
+	if err != nil {
+		return nil, err
+	}
+	return doc.Elements, nil
+}
@@ -0,0 +1,125 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	input := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape" width="100" height="50">` +
+		`<!-- designer template -->` +
+		`<defs><linearGradient id="g"><stop offset="0%" stop-color="#000"/></linearGradient></defs>` +
+		`<g inkscape:label="Layer 1" data-slot="chart">` +
+		`<rect x="0" y="0" width="10" height="10" fill="url(#g)"/>` +
+		`<text x="5" y="20">Total: <tspan font-weight="bold">42</tspan> items &amp; more</text>` +
+		`<use xlink:href="#g"/>` +
+		`</g>` +
+		`</svg>`
+
+	doc, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var b strings.Builder
+	if _, err := doc.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if b.String() != input {
+		t.Errorf("Round trip mismatch.\nExpected:\n%s\nGot:\n%s", input, b.String())
+	}
+}
+
+func TestParseMixedContent(t *testing.T) {
+	doc, err := ParseString(`<svg><text>a<tspan>b</tspan>c<!--note--></text></svg>`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	text := doc.Elements[0]
+	children := text.Children()
+	if len(children) != 4 {
+		t.Fatalf("Expected 4 child nodes, got %d", len(children))
+	}
+	if children[0] != CharData("a") || children[2] != CharData("c") {
+		t.Errorf("Expected text nodes to be preserved, got %v", children)
+	}
+	if children[3] != Comment("note") {
+		t.Errorf("Expected comment to be preserved, got %v", children[3])
+	}
+}
+
+func TestParseProlog(t *testing.T) {
+	input := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<svg width=\"10\" height=\"10\"/>"
+
+	doc, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.Prolog) != 1 {
+		t.Fatalf("Expected XML declaration in prolog, got %d nodes", len(doc.Prolog))
+	}
+	if doc.String() != input {
+		t.Errorf("Expected:\n%s\nGot:\n%s", input, doc.String())
+	}
+}
+
+func TestParseInjectIntoTemplate(t *testing.T) {
+	doc, err := ParseString(`<svg xmlns="http://www.w3.org/2000/svg"><g id="slot"/></svg>`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	slot := doc.Elements[0].(*GenericElement)
+	slot.Elements = append(slot.Elements, &RectElement{Width: 10, Height: 10})
+
+	if !strings.Contains(doc.String(), `<g id="slot"><rect`) {
+		t.Errorf("Expected injected content, got:\n%s", doc.String())
+	}
+	if id := doc.NewID("slot"); id == "slot" {
+		t.Error("Expected parsed IDs to be registered")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"Empty", ""},
+		{"Not SVG", `<html/>`},
+		{"Unclosed", `<svg><g></svg>`},
+		{"Truncated", `<svg><g>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseString(tt.input); err == nil {
+				t.Errorf("Expected error parsing %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestParseEpilogAndRootName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"trailing comment", "<svg width=\"10\"/>\n<!-- generated -->"},
+		{"trailing processing instruction", "<svg width=\"10\"/>\n<?app state=\"1\"?>"},
+		{"prefixed root", `<svg:svg xmlns:svg="http://www.w3.org/2000/svg"><svg:rect width="1"/></svg:svg>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if doc.String() != tt.input {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.input, doc.String())
+			}
+		})
+	}
+}
@@ -22,49 +22,49 @@ func NewPathBuilder() *PathBuilder {
 
 // MoveTo moves the pen to the specified point without drawing
 func (pb *PathBuilder) MoveTo(x, y float64) *PathBuilder {
-	fmt.Fprintf(&pb.commands, "M %.2f %.2f ", x, y)
+	pb.command("M", []float64{x, y})
 	return pb
 }
 
 // LineTo draws a line from the current point to the specified point
 func (pb *PathBuilder) LineTo(x, y float64) *PathBuilder {
-	fmt.Fprintf(&pb.commands, "L %.2f %.2f ", x, y)
+	pb.command("L", []float64{x, y})
 	return pb
 }
 
 // HorizontalLineTo draws a horizontal line to the specified x coordinate
 func (pb *PathBuilder) HorizontalLineTo(x float64) *PathBuilder {
-	fmt.Fprintf(&pb.commands, "H %.2f ", x)
+	pb.command("H", []float64{x})
 	return pb
 }
 
 // VerticalLineTo draws a vertical line to the specified y coordinate
 func (pb *PathBuilder) VerticalLineTo(y float64) *PathBuilder {
-	fmt.Fprintf(&pb.commands, "V %.2f ", y)
+	pb.command("V", []float64{y})
 	return pb
 }
 
 // CurveTo draws a cubic Bézier curve
 func (pb *PathBuilder) CurveTo(x1, y1, x2, y2, x, y float64) *PathBuilder {
-	fmt.Fprintf(&pb.commands, "C %.2f %.2f, %.2f %.2f, %.2f %.2f ", x1, y1, x2, y2, x, y)
+	pb.command("C", []float64{x1, y1}, []float64{x2, y2}, []float64{x, y})
 	return pb
 }
 
 // SmoothCurveTo draws a smooth cubic Bézier curve (first control point is reflection of previous)
 func (pb *PathBuilder) SmoothCurveTo(x2, y2, x, y float64) *PathBuilder {
-	fmt.Fprintf(&pb.commands, "S %.2f %.2f, %.2f %.2f ", x2, y2, x, y)
+	pb.command("S", []float64{x2, y2}, []float64{x, y})
 	return pb
 }
 
 // QuadraticCurveTo draws a quadratic Bézier curve
 func (pb *PathBuilder) QuadraticCurveTo(x1, y1, x, y float64) *PathBuilder {
-	fmt.Fprintf(&pb.commands, "Q %.2f %.2f, %.2f %.2f ", x1, y1, x, y)
+	pb.command("Q", []float64{x1, y1}, []float64{x, y})
 	return pb
 }
 
 // SmoothQuadraticCurveTo draws a smooth quadratic Bézier curve
 func (pb *PathBuilder) SmoothQuadraticCurveTo(x, y float64) *PathBuilder {
-	fmt.Fprintf(&pb.commands, "T %.2f %.2f ", x, y)
+	pb.command("T", []float64{x, y})
 	return pb
 }
 
@@ -75,7 +75,9 @@ func (pb *PathBuilder) SmoothQuadraticCurveTo(x, y float64) *PathBuilder {
 // sweepFlag: 0 for counter-clockwise, 1 for clockwise
 // x, y: end point
 func (pb *PathBuilder) ArcTo(rx, ry, xAxisRotation float64, largeArcFlag, sweepFlag int, x, y float64) *PathBuilder {
-	fmt.Fprintf(&pb.commands, "A %.2f %.2f %.2f %d %d %.2f %.2f ", rx, ry, xAxisRotation, largeArcFlag, sweepFlag, x, y)
+	fmt.Fprintf(&pb.commands, "A %s %s %s %d %d %s %s ",
+		formatFloat(rx), formatFloat(ry), formatFloat(xAxisRotation),
+		largeArcFlag, sweepFlag, formatFloat(x), formatFloat(y))
 	return pb
 }
 
@@ -85,6 +87,22 @@ func (pb *PathBuilder) Close() *PathBuilder {
 	return pb
 }
 
+// command writes a command letter followed by its coordinate groups,
+// e.g. "C x1 y1, x2 y2, x y "
+func (pb *PathBuilder) command(letter string, groups ...[]float64) {
+	pb.commands.WriteString(letter)
+	for i, group := range groups {
+		if i > 0 {
+			pb.commands.WriteString(",")
+		}
+		for _, v := range group {
+			pb.commands.WriteString(" ")
+			pb.commands.WriteString(formatFloat(v))
+		}
+	}
+	pb.commands.WriteString(" ")
+}
+
 // String returns the path data string
 func (pb *PathBuilder) String() string {
 	return strings.TrimSpace(pb.commands.String())
@@ -0,0 +1,305 @@
+package svg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathSegment is a single path command with absolute coordinates
+type PathSegment struct {
+	Command byte      // Upper-case command letter (M, L, H, V, C, S, Q, T, A, Z)
+	Args    []float64 // Absolute arguments; arc flags are stored as 0 or 1
+}
+
+// pathArgCounts is the number of arguments each command takes
+var pathArgCounts = map[byte]int{
+	'M': 2, 'L': 2, 'H': 1, 'V': 1, 'C': 6, 'S': 4, 'Q': 4, 'T': 2, 'A': 7, 'Z': 0,
+}
+
+// ParsePathData parses SVG path data into segments with absolute coordinates
+func ParsePathData(d string) ([]PathSegment, error) {
+	p := &pathParser{s: d}
+
+	var segments []PathSegment
+	var cmd byte
+	var curX, curY, startX, startY float64
+
+	for {
+		p.skipSeparators()
+		if p.done() {
+			break
+		}
+
+		if c := p.s[p.pos]; isPathCommand(c) {
+			cmd = c
+			p.pos++
+		} else if cmd == 0 {
+			return nil, fmt.Errorf("path data must start with a command: %q", d)
+		} else if cmd == 'Z' || cmd == 'z' {
+			return nil, fmt.Errorf("unexpected number after close path at offset %d", p.pos)
+		}
+
+		upper := cmd &^ 0x20
+		relative := cmd != upper
+		count := pathArgCounts[upper]
+
+		args := make([]float64, count)
+		for i := 0; i < count; i++ {
+			var v float64
+			var err error
+			if upper == 'A' && (i == 3 || i == 4) {
+				v, err = p.flag()
+			} else {
+				v, err = p.number()
+			}
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+
+		if relative {
+			switch upper {
+			case 'H':
+				args[0] += curX
+			case 'V':
+				args[0] += curY
+			case 'A':
+				args[5] += curX
+				args[6] += curY
+			default:
+				for i := 0; i+1 < count; i += 2 {
+					args[i] += curX
+					args[i+1] += curY
+				}
+			}
+		}
+
+		segments = append(segments, PathSegment{Command: upper, Args: args})
+
+		switch upper {
+		case 'M':
+			curX, curY = args[0], args[1]
+			startX, startY = curX, curY
+			// Subsequent coordinate pairs are implicit line-tos
+			if relative {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'Z':
+			curX, curY = startX, startY
+		case 'H':
+			curX = args[0]
+		case 'V':
+			curY = args[0]
+		default:
+			curX, curY = args[count-2], args[count-1]
+		}
+	}
+
+	return segments, nil
+}
+
+// pathParser tokenizes path data
+type pathParser struct {
+	s   string
+	pos int
+}
+
+func (p *pathParser) done() bool { return p.pos >= len(p.s) }
+
+func (p *pathParser) skipSeparators() {
+	for !p.done() {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *pathParser) number() (float64, error) {
+	p.skipSeparators()
+	start := p.pos
+	if !p.done() && (p.s[p.pos] == '-' || p.s[p.pos] == '+') {
+		p.pos++
+	}
+	sawDot := false
+loop:
+	for !p.done() {
+		c := p.s[p.pos]
+		switch {
+		case c >= '0' && c <= '9':
+			p.pos++
+		case c == '.' && !sawDot:
+			sawDot = true
+			p.pos++
+		case (c == 'e' || c == 'E') && p.pos > start:
+			p.pos++
+			if !p.done() && (p.s[p.pos] == '-' || p.s[p.pos] == '+') {
+				p.pos++
+			}
+		default:
+			break loop
+		}
+	}
+	if start == p.pos {
+		return 0, fmt.Errorf("expected number at offset %d in path data", start)
+	}
+	return strconv.ParseFloat(p.s[start:p.pos], 64)
+}
+
+func (p *pathParser) flag() (float64, error) {
+	p.skipSeparators()
+	if p.done() || (p.s[p.pos] != '0' && p.s[p.pos] != '1') {
+		return 0, fmt.Errorf("expected arc flag at offset %d in path data", p.pos)
+	}
+	v := float64(p.s[p.pos] - '0')
+	p.pos++
+	return v, nil
+}
+
+func isPathCommand(c byte) bool {
+	_, ok := pathArgCounts[c&^0x20]
+	return ok && ((c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'))
+}
+
+// shortenPathData re-encodes path data in its shortest form
+func shortenPathData(d string, precision int) (string, error) {
+	segments, err := ParsePathData(d)
+	if err != nil {
+		return "", err
+	}
+	return encodePathData(segments, precision), nil
+}
+
+// encodePathData writes segments choosing, per segment, the shorter of the
+// absolute and relative forms and omitting repeated command letters
+func encodePathData(segments []PathSegment, precision int) string {
+	var b strings.Builder
+	var prevCmd byte
+	var curX, curY, startX, startY float64
+
+	for _, seg := range segments {
+		args := make([]float64, len(seg.Args))
+		for i, v := range seg.Args {
+			args[i] = roundTo(v, precision)
+		}
+		cmd := seg.Command
+
+		// Axis-aligned lines are shorter as H/V
+		if cmd == 'L' {
+			if args[1] == curY {
+				cmd, args = 'H', args[:1]
+			} else if args[0] == curX {
+				cmd, args = 'V', args[1:]
+			}
+		}
+
+		abs := formatPathArgs(cmd, args, precision)
+		rel := formatPathArgs(cmd, relativeArgs(cmd, args, curX, curY, precision), precision)
+
+		absText := encodeCommand(b.String(), prevCmd, cmd, abs)
+		relText := encodeCommand(b.String(), prevCmd, cmd|0x20, rel)
+
+		letter, text := cmd, absText
+		if len(relText) < len(absText) {
+			letter, text = cmd|0x20, relText
+		}
+		b.WriteString(text)
+		prevCmd = letter
+
+		switch cmd {
+		case 'M':
+			curX, curY = args[0], args[1]
+			startX, startY = curX, curY
+		case 'Z':
+			curX, curY = startX, startY
+		case 'H':
+			curX = args[0]
+		case 'V':
+			curY = args[0]
+		default:
+			curX, curY = args[len(args)-2], args[len(args)-1]
+		}
+	}
+
+	return b.String()
+}
+
+// encodeCommand returns the text for a command, omitting its letter when
+// it repeats the previous command. Moveto is never repeated implicitly,
+// since implicit coordinates after it mean lineto.
+func encodeCommand(prev string, prevCmd, letter byte, args string) string {
+	implicit := letter == prevCmd && letter != 'M' && letter != 'm' && letter != 'Z' && letter != 'z'
+	if !implicit {
+		return string(letter) + args
+	}
+	if needsSeparator(prev, args) {
+		return " " + args
+	}
+	return args
+}
+
+// relativeArgs converts absolute arguments to be relative to the current point
+func relativeArgs(cmd byte, args []float64, curX, curY float64, precision int) []float64 {
+	rel := make([]float64, len(args))
+	copy(rel, args)
+	switch cmd {
+	case 'Z':
+	case 'H':
+		rel[0] = roundTo(args[0]-curX, precision)
+	case 'V':
+		rel[0] = roundTo(args[0]-curY, precision)
+	case 'A':
+		rel[5] = roundTo(args[5]-curX, precision)
+		rel[6] = roundTo(args[6]-curY, precision)
+	default:
+		for i := 0; i+1 < len(args); i += 2 {
+			rel[i] = roundTo(args[i]-curX, precision)
+			rel[i+1] = roundTo(args[i+1]-curY, precision)
+		}
+	}
+	return rel
+}
+
+// formatPathArgs joins arguments using the fewest separators
+func formatPathArgs(cmd byte, args []float64, precision int) string {
+	var b strings.Builder
+	for i, v := range args {
+		var s string
+		if cmd == 'A' && (i == 3 || i == 4) {
+			s = strconv.Itoa(int(v))
+		} else {
+			s = compactNumber(v, precision)
+		}
+		if i > 0 && needsSeparator(b.String(), s) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// needsSeparator reports whether a separator is needed between two tokens
+func needsSeparator(prev, next string) bool {
+	if prev == "" || next == "" {
+		return false
+	}
+	last := prev[len(prev)-1]
+	if isPathCommand(last) {
+		return false
+	}
+	if next[0] == '-' {
+		return false
+	}
+	if next[0] == '.' {
+		// ".5" directly after a number that already has a fraction starts a new number
+		i := strings.LastIndexAny(prev, " ,-abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+		return !strings.Contains(prev[i+1:], ".")
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package svg
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ExportPDF combines SVG pages into one PDF, such as those from RenderPages
+//
+// Each page is rasterized as by Export and embedded as a JPEG image, so the
+// PDF prints as shown but its text cannot be selected. The page size is the
+// SVG's width and height in CSS pixels, at 96 per inch; opts.Width and
+// opts.Height only set the size of the image, as for Export.
+func ExportPDF(pages []string, opts ExportOptions) ([]byte, error) {
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no pages to export")
+	}
+
+	pdf := &pdfWriter{}
+	pdf.object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	// Each page takes three objects: the page, its content and its image
+	kids := ""
+	for i := range pages {
+		kids += fmt.Sprintf("%d 0 R ", 3+3*i)
+	}
+	pdf.object(fmt.Sprintf("<< /Type /Pages /Kids [ %s] /Count %d >>", kids, len(pages)))
+
+	for i, page := range pages {
+		// Page size in points
+		width, height, err := svgPageSize(page)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", i+1, err)
+		}
+		width, height = width*72/96, height*72/96
+
+		img, err := rasterizeImage(page, opts)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", i+1, err)
+		}
+		var jpg bytes.Buffer
+		if err := encodeJPEG(&jpg, img, opts.Quality); err != nil {
+			return nil, fmt.Errorf("page %d: %w", i+1, err)
+		}
+		size := img.Bounds().Size()
+
+		pageID := 3 + 3*i
+		pdf.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /XObject << /Im0 %d 0 R >> >> /Contents %d 0 R >>",
+			formatDimension(width), formatDimension(height), pageID+2, pageID+1))
+		pdf.stream("", []byte(fmt.Sprintf("q %s 0 0 %s 0 0 cm /Im0 Do Q", formatDimension(width), formatDimension(height))))
+		pdf.stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode ",
+			size.X, size.Y), jpg.Bytes())
+	}
+
+	return pdf.finish(), nil
+}
+
+// svgPageSize returns the width and height of an SVG in CSS pixels, from
+// its width and height, or else its viewBox
+func svgPageSize(svgData string) (float64, float64, error) {
+	root, err := parseSVG(svgData)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse SVG: %w", err)
+	}
+	width, height := parseCoordinate(root.Attributes["width"]), parseCoordinate(root.Attributes["height"])
+	if viewBox, ok := parseViewBox(root.Attributes["viewBox"]); ok {
+		if width <= 0 {
+			width = viewBox.Width
+		}
+		if height <= 0 {
+			height = viewBox.Height
+		}
+	}
+	if width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("SVG has no width and height")
+	}
+	return width, height, nil
+}
+
+// pdfWriter writes numbered PDF objects and the cross-reference table
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// object writes the next object, numbered from 1
+func (p *pdfWriter) object(body string) {
+	if p.buf.Len() == 0 {
+		p.buf.WriteString("%PDF-1.4\n")
+	}
+	p.offsets = append(p.offsets, p.buf.Len())
+	fmt.Fprintf(&p.buf, "%d 0 obj\n%s\nendobj\n", len(p.offsets), body)
+}
+
+// stream writes the next object as a stream with the given dictionary entries
+func (p *pdfWriter) stream(dict string, data []byte) {
+	p.object(fmt.Sprintf("<< %s/Length %d >>\nstream\n%s\nendstream", dict, len(data), data))
+}
+
+// finish writes the cross-reference table and trailer and returns the PDF
+func (p *pdfWriter) finish() []byte {
+	xref := p.buf.Len()
+	fmt.Fprintf(&p.buf, "xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, offset := range p.offsets {
+		fmt.Fprintf(&p.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&p.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, xref)
+	return p.buf.Bytes()
+}
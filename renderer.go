@@ -2,6 +2,7 @@ package svg
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/SCKelemen/layout"
@@ -10,20 +11,39 @@ import (
 // Renderer renders layout trees to SVG
 type Renderer struct {
 	options      Options
-	clipPath     *ClipPathManager
-	builder      strings.Builder
+	clipPath     *ClipPathManager // Clip paths registered through GetClipPathManager, kept across renders
+	clips        *ClipPathManager // Clip paths generated while rendering
 	defaultStyle Style
+	theme        *Theme          // Theme used for node styles; colors are var() references when adaptive
+	classes      *StyleClasses   // Generated style classes when DeduplicateStyles is set
+	defs         []Element       // Generated definitions such as gradients and filters
+	defIDs       map[string]bool // IDs of defs, so a named definition is written once
+	ids          *IDAllocator    // Allocates the IDs of generated definitions
+	debug        []Element       // Debug overlay, drawn over the content when Options.Debug is set
+	cull         *culling        // Viewport culling and minimum node size, if enabled
 }
 
 // NewRenderer creates a new SVG renderer with the given options
 func NewRenderer(opts Options) *Renderer {
+	ids := newIDAllocator(opts)
 	return &Renderer{
 		options:  opts,
-		clipPath: NewClipPathManager(),
+		clipPath: NewClipPathManagerWithIDs(ids),
+		clips:    NewClipPathManagerWithIDs(ids),
+		ids:      ids,
 		defaultStyle: Style{
 			Fill:   "#e0e0e0",
 			Stroke: "#333",
 		},
+		theme: styleTheme(opts),
+	}
+}
+
+// resetStyleClasses starts a new set of generated style classes
+func (r *Renderer) resetStyleClasses() {
+	r.classes = nil
+	if r.options.DeduplicateStyles {
+		r.classes = NewStyleClasses(r.options.StyleClassPrefix)
 	}
 }
 
@@ -35,152 +55,456 @@ func RenderToSVG(root *layout.Node, opts Options) string {
 
 // Render renders the layout tree to SVG
 func (r *Renderer) Render(root *layout.Node) string {
-	r.builder.Reset()
+	return r.document(func(nw *nodeWriter) {
+		r.renderNode(nw, root, 0)
+	})
+}
+
+// document returns a complete SVG document whose content is written by
+// render; the content is rendered first, so generated definitions can go
+// in <defs>. Generated IDs avoid the reserved IDs used by the content.
+func (r *Renderer) document(render func(nw *nodeWriter), reserved ...string) string {
+	r.reset()
+	for _, id := range reserved {
+		r.ids.Reserve(id)
+	}
+
+	var b strings.Builder
+	cw := &countingWriter{w: &b}
+	r.writeStart(cw)
 
+	var content strings.Builder
+	render(&nodeWriter{countingWriter: countingWriter{w: &content}})
+
+	cw.writeString("<defs>\n")
+	cw.writeString(r.classStyles())
+	cw.writeString(r.styleSheets())
+	cw.writeString(r.renderedDefs())
+	cw.writeString("</defs>\n")
+
+	r.writeBackground(cw)
+	cw.writeString(content.String())
+	r.writeEnd(cw)
+
+	return b.String()
+}
+
+// RenderTo renders the layout tree to w as it walks the tree
+//
+// Unlike Render, it does not hold the content in memory: definitions
+// generated while rendering, such as clip paths and style classes, are
+// written in a second <defs> element after the content. SVG references
+// resolve anywhere in the document, so the result looks the same. Generated
+// style classes then follow the stylesheets, but have no specificity, so
+// only user rules without specificity, such as :where(), can differ.
+func (r *Renderer) RenderTo(w io.Writer, root *layout.Node) (int64, error) {
+	r.reset()
+
+	nw := &nodeWriter{countingWriter: countingWriter{w: w}}
+	cw := &nw.countingWriter
+	r.writeStart(cw)
+
+	if ss := r.styleSheets(); ss != "" {
+		cw.writeString("<defs>\n")
+		cw.writeString(ss)
+		cw.writeString("</defs>\n")
+	}
+	r.writeBackground(cw)
+
+	r.renderNode(nw, root, 0)
+
+	if defs := r.classStyles() + r.renderedDefs(); defs != "" {
+		cw.writeString("<defs>\n")
+		cw.writeString(defs)
+		cw.writeString("</defs>\n")
+	}
+	r.writeEnd(cw)
+
+	return cw.n, cw.err
+}
+
+// reset clears the state of a previous render, so rendering the same
+// input again produces the same output
+func (r *Renderer) reset() {
+	r.ids.Reset()
+	for _, cp := range r.clipPath.paths {
+		r.ids.Reserve(cp.ID)
+	}
+	r.clips = NewClipPathManagerWithIDs(r.ids)
+	r.resetStyleClasses()
+	r.defs = nil
+	r.defIDs = nil
+	r.debug = nil
+	r.cull = newCulling(r.options)
+}
+
+// writeStart writes the XML declaration, the <svg> start tag and the
+// document's title and description
+func (r *Renderer) writeStart(cw *countingWriter) {
 	// XML declaration
 	if r.options.IncludeXMLDeclaration {
-		r.builder.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
-		r.builder.WriteString("\n")
+		cw.writeString(`<?xml version="1.0" encoding="UTF-8"?>`)
+		cw.writeString("\n")
 	}
 
 	// Start SVG tag
-	r.builder.WriteString("<svg")
+	cw.writeString("<svg")
 
 	// Width and height
-	r.builder.WriteString(fmt.Sprintf(` width="%.0f" height="%.0f"`, r.options.Width, r.options.Height))
+	cw.writeString(fmt.Sprintf(` width="%s" height="%s"`, formatDimension(r.options.Width), formatDimension(r.options.Height)))
 
 	// ViewBox
 	viewBox := r.options.ViewBox
 	if viewBox == "" {
-		viewBox = fmt.Sprintf("0 0 %.0f %.0f", r.options.Width, r.options.Height)
+		viewBox = fmt.Sprintf("0 0 %s %s", formatDimension(r.options.Width), formatDimension(r.options.Height))
 	}
-	r.builder.WriteString(fmt.Sprintf(` viewBox="%s"`, viewBox))
+	cw.writeString(formatAttrs([]Attr{{"viewBox", viewBox}}))
 
 	// Namespace
 	if r.options.Namespace {
-		r.builder.WriteString(` xmlns="http://www.w3.org/2000/svg"`)
+		cw.writeString(` xmlns="http://www.w3.org/2000/svg"`)
 	}
 
 	// PreserveAspectRatio
 	if r.options.PreserveAspectRatio != "" {
-		r.builder.WriteString(fmt.Sprintf(` preserveAspectRatio="%s"`, r.options.PreserveAspectRatio))
+		cw.writeString(formatAttrs([]Attr{{"preserveAspectRatio", r.options.PreserveAspectRatio}}))
 	}
 
-	r.builder.WriteString(">")
-	r.builder.WriteString("\n")
+	// Accessibility
+	ariaAttrs, titles := r.rootAccessibility()
+	cw.writeString(formatAttrs(ariaAttrs))
 
-	// Defs section
-	r.builder.WriteString("<defs>")
-	r.builder.WriteString("\n")
+	cw.writeString(">")
+	cw.writeString("\n")
+	cw.writeString(titles)
+}
 
-	// Stylesheet
+// styleSheets returns the theme and user stylesheets, which are known
+// before rendering
+func (r *Renderer) styleSheets() string {
+	var b strings.Builder
+	if ss := themeStyleSheet(r.options); ss != nil {
+		b.WriteString(ss.ToSVG())
+		b.WriteString("\n")
+	}
 	if r.options.StyleSheet != nil {
-		r.builder.WriteString(r.options.StyleSheet.ToSVG())
-		r.builder.WriteString("\n")
+		b.WriteString(r.options.StyleSheet.ToSVG())
+		b.WriteString("\n")
 	}
+	return b.String()
+}
 
-	// Render nodes (this may add clipPaths)
-	content := r.renderNode(root, 0)
-
-	// ClipPaths (added during rendering)
-	if clipDefs := r.clipPath.ToSVGDefs(); clipDefs != "" {
-		r.builder.WriteString("    ")
-		r.builder.WriteString(clipDefs)
+// classStyles returns the style classes generated while rendering
+// They replace presentation attributes, so they come before other
+// stylesheets, which override them
+func (r *Renderer) classStyles() string {
+	if ss := r.classStyleSheet(); ss != nil {
+		return ss.ToSVG() + "\n"
 	}
+	return ""
+}
 
-	r.builder.WriteString("</defs>")
-	r.builder.WriteString("\n")
+// renderedDefs returns the clip paths, gradients, filters and other
+// definitions generated while rendering
+func (r *Renderer) renderedDefs() string {
+	var b strings.Builder
+	for _, clips := range []*ClipPathManager{r.clipPath, r.clips} {
+		if clipDefs := clips.ToSVGDefs(); clipDefs != "" {
+			b.WriteString("    ")
+			b.WriteString(clipDefs)
+		}
+	}
+	b.WriteString(r.generatedDefs())
+	return b.String()
+}
 
-	// Background
-	if r.options.BackgroundColor != "" {
-		r.builder.WriteString(fmt.Sprintf(`<rect width="%.0f" height="%.0f" fill="%s"/>`,
-			r.options.Width, r.options.Height, r.options.BackgroundColor))
-		r.builder.WriteString("\n")
+// writeBackground writes the background rectangle, if any
+func (r *Renderer) writeBackground(cw *countingWriter) {
+	if background := r.backgroundColor(); background != "" {
+		cw.writeString(fmt.Sprintf(`<rect width="%s" height="%s" fill="%s"/>`,
+			formatDimension(r.options.Width), formatDimension(r.options.Height), escapeXML(background)))
+		cw.writeString("\n")
 	}
+}
 
-	// Content
-	r.builder.WriteString(content)
+// writeEnd writes the elements drawn after the content and closes the <svg>
+func (r *Renderer) writeEnd(cw *countingWriter) {
+	cw.writeString(r.dataTable())
+	cw.writeString(r.debugOverlayGroup())
+	cw.writeString("</svg>")
+}
 
-	// End SVG tag
-	r.builder.WriteString("</svg>")
+// renderNode renders a top-level layout node and its children
+// index is the node's position among the top-level nodes
+func (r *Renderer) renderNode(nw *nodeWriter, node *layout.Node, index int) {
+	r.renderNodeIn(nw, node, index, "")
+}
 
-	return r.builder.String()
+// renderNodeIn renders a top-level node written inside a group with the
+// given transform, which the debug overlay follows
+func (r *Renderer) renderNodeIn(nw *nodeWriter, node *layout.Node, index int, transform string) {
+	r.renderSubtree(nw, node, &RenderContext{
+		Path:      []*layout.Node{node},
+		Renderer:  r,
+		index:     []int{index},
+		transform: transform,
+	})
 }
 
-// renderNode recursively renders a layout node and its children
-func (r *Renderer) renderNode(node *layout.Node, depth int) string {
-	if node == nil {
-		return ""
+// renderSubtree recursively renders a layout node and its children
+func (r *Renderer) renderSubtree(nw *nodeWriter, node *layout.Node, ctx *RenderContext) {
+	// Layout rects under a transform are not where the node is drawn, so
+	// culling stops at the first transformed ancestor
+	if node == nil || (!ctx.transformed && !r.cull.visible(node)) {
+		return
 	}
+	depth := ctx.Depth
 
 	// Allow custom rendering
 	if r.options.RenderFunc != nil {
 		if custom := r.options.RenderFunc(node, depth); custom != "" {
-			return custom
+			nw.write(custom)
+			return
 		}
 	}
 
-	var b strings.Builder
 	rect := node.Rect
+	indent := strings.Repeat("  ", depth+1)
+	writeElements := func(elements []Element) {
+		for _, element := range elements {
+			nw.write(indent)
+			nw.writeElement(element)
+			nw.write("\n")
+		}
+	}
+
+	// Get style and box decorations for this node
+	ctx.Style = r.nodeStyle(node, depth)
+	box, decorated := r.boxStyle(node, depth)
 
-	// Get style for this node
-	style := r.defaultStyle
-	if r.options.StyleFunc != nil {
-		style = r.options.StyleFunc(node, depth)
+	// Extensions draw under the node and choose its children
+	var before []Element
+	children := node.Children
+	for _, ext := range r.options.NodeRenderers {
+		before = append(before, ext.Before(node, ctx)...)
 	}
+	for _, ext := range r.options.NodeRenderers {
+		children = ext.Children(node, children, ctx)
+	}
+	style := ctx.Style
 
 	// Get transform
 	transform := GetTransformFromNode(node)
+	ctx.transformed = ctx.transformed || transform != ""
+	ctx.transform = strings.TrimSpace(ctx.transform + " " + transform)
 
-	// Start group if there's a transform or children
+	// The overlay is drawn at the root, so it repeats the node's transforms
+	if r.options.Debug {
+		r.debug = append(r.debug, transformed(ctx.transform, r.debugOverlay(node))...)
+	}
+
+	// Start group if there's a transform, children, an accessible name or
+	// attributes for the node
 	hasTransform := transform != ""
-	hasChildren := len(node.Children) > 0
+	hasChildren := len(children) > 0
+	var groupAttrs []Attr
+	if hasTransform {
+		groupAttrs = append(groupAttrs, Attr{"transform", transform})
+	}
+	groupAttrs = append(groupAttrs, r.nodeARIA(node, depth, hasChildren)...)
+	nodeAttrs := r.nodeAttributes(node, ctx)
+	groupAttrs = append(groupAttrs, nodeAttrs.attrs()...)
+	hasGroup := hasChildren || len(groupAttrs) > 0 || nodeAttrs.Href != "" || nodeAttrs.Tooltip != ""
+
+	// Wrap the node in a link
+	link := &LinkElement{Href: nodeAttrs.Href, Target: nodeAttrs.Target}
+	hasLink := nodeAttrs.Href != ""
+	if hasLink {
+		nw.write("<a")
+		nw.write(formatAttrs(link.Attributes()))
+		nw.write(">")
+	}
 
-	if hasTransform || hasChildren {
-		if hasTransform {
-			b.WriteString(fmt.Sprintf(`<g transform="%s">`, transform))
-		} else {
-			b.WriteString("<g>")
+	if hasGroup {
+		nw.write("<g")
+		nw.write(formatAttrs(groupAttrs))
+		nw.write(">")
+		nw.write("\n")
+	}
+	if nodeAttrs.Tooltip != "" {
+		writeElements([]Element{&TitleElement{Content: nodeAttrs.Tooltip}})
+	}
+
+	writeElements(before)
+
+	// The node's text and children share one clip path for its content box
+	var clipID string
+	contentClip := func() string {
+		if clipID == "" {
+			clipID = r.contentClipPath(node, box)
 		}
-		b.WriteString("\n")
+		return clipID
 	}
 
-	// Render the node itself as a rectangle
-	// Only render if it has non-zero dimensions
-	if rect.Width > 0 && rect.Height > 0 {
-		indent := strings.Repeat("  ", depth+1)
-		b.WriteString(indent)
-		b.WriteString(Rect(rect.X, rect.Y, rect.Width, rect.Height, style))
-		b.WriteString("\n")
+	if custom, ok := r.extensionRender(node, ctx); ok {
+		writeElements(custom)
+	} else {
+		// Render the node's box (a rectangle, or its decorations)
+		// Only render if it has non-zero dimensions
+		if rect.Width > 0 && rect.Height > 0 {
+			writeElements(r.boxElements(node, box, decorated, style))
+		}
+
+		// Render the node's text inside its content box
+		if text := r.renderNodeText(node, depth, box.Overflow, contentClip); text != "" {
+			nw.write(indent)
+			nw.write(text)
+			nw.write("\n")
+		}
+	}
+
+	// Clip children to the content box when overflow is hidden
+	clipChildren := hasChildren && box.Overflow == OverflowHidden
+	if clipChildren {
+		nw.write(indent)
+		nw.write("<g")
+		nw.write(formatAttrs([]Attr{{"clip-path", URL(contentClip())}}))
+		nw.write(">\n")
 	}
 
-	// Render children
-	for _, child := range node.Children {
-		childContent := r.renderNode(child, depth+1)
-		if childContent != "" {
-			indent := strings.Repeat("  ", depth+1)
-			b.WriteString(indent)
-			b.WriteString(childContent)
+	// Render children, indenting the first line of those that write output
+	// Children below the minimum size are drawn as aggregate cells instead,
+	// in place of the first child in each cell
+	lod := r.cull.aggregate()
+	for i, child := range children {
+		if bounds, ok := r.cull.tiny(child); ok && !ctx.transformed {
+			if r.cull.visible(child) {
+				if cell := lod.add(r, bounds, aggregateFill(r.nodeStyle(child, depth+1))); cell != nil {
+					writeElements([]Element{cell})
+				}
+			}
+			continue
 		}
+		nw.prefix = indent
+		r.renderSubtree(nw, child, ctx.childContext(child, i))
+		nw.prefix = ""
+	}
+
+	if clipChildren {
+		nw.write(indent)
+		nw.write("</g>")
+		nw.write("\n")
+	}
+
+	// Extensions draw over the node and its children
+	for _, ext := range r.options.NodeRenderers {
+		writeElements(ext.After(node, ctx))
 	}
 
 	// End group
-	if hasTransform || hasChildren {
-		indent := strings.Repeat("  ", depth)
-		b.WriteString(indent)
-		b.WriteString("</g>")
-		b.WriteString("\n")
+	if hasGroup {
+		nw.write(strings.Repeat("  ", depth))
+		nw.write("</g>")
+		if hasLink {
+			nw.write("</a>")
+		}
+		nw.write("\n")
 	}
+}
 
-	return b.String()
+// extensionRender returns the elements of the first NodeRenderer that
+// replaces the node's default rendering
+func (r *Renderer) extensionRender(node *layout.Node, ctx *RenderContext) ([]Element, bool) {
+	for _, ext := range r.options.NodeRenderers {
+		if elements, ok := ext.Render(node, ctx); ok {
+			return elements, true
+		}
+	}
+	return nil, false
+}
+
+// nodeStyle returns the style for a node: StyleFunc takes precedence,
+// then the theme, then the renderer's default style
+func (r *Renderer) nodeStyle(node *layout.Node, depth int) Style {
+	switch {
+	case r.options.StyleFunc != nil:
+		return r.options.StyleFunc(node, depth)
+	case r.theme != nil:
+		return r.theme.DepthStyle(depth)
+	}
+	return r.defaultStyle
+}
+
+// classStyleSheet returns the stylesheet of generated style classes, if any
+func (r *Renderer) classStyleSheet() *StyleSheet {
+	if r.classes == nil || r.classes.Len() == 0 {
+		return nil
+	}
+	return r.classes.StyleSheet()
+}
+
+// backgroundColor returns the background color, falling back to the theme
+func (r *Renderer) backgroundColor() string {
+	if r.options.BackgroundColor == "" && r.theme != nil {
+		return r.theme.Palette.Background
+	}
+	return r.options.BackgroundColor
+}
+
+// styleTheme returns the theme used to style nodes
+// With a DarkTheme, palette colors are var() references that follow the
+// viewer's color scheme
+func styleTheme(opts Options) *Theme {
+	switch {
+	case opts.DarkTheme != nil:
+		return lightTheme(opts).colorVars()
+	case opts.Theme != nil:
+		return opts.Theme
+	}
+	return nil
+}
+
+// themeStyleSheet returns the stylesheet generated from the theme options, if any
+func themeStyleSheet(opts Options) *StyleSheet {
+	switch {
+	case opts.DarkTheme != nil:
+		return AdaptiveStyleSheet(lightTheme(opts), opts.DarkTheme)
+	case opts.Theme != nil:
+		return opts.Theme.StyleSheet()
+	}
+	return nil
+}
+
+// lightTheme returns Options.Theme, or LightTheme if it is not set
+func lightTheme(opts Options) *Theme {
+	if opts.Theme != nil {
+		return opts.Theme
+	}
+	return LightTheme()
 }
 
 // GetClipPathManager returns the clipPath manager for custom clipPath creation
+// Clip paths added to it are written by every render
 func (r *Renderer) GetClipPathManager() *ClipPathManager {
 	return r.clipPath
 }
 
+// IDs returns the allocator for IDs of generated definitions
+// IDs are numbered per renderer, so the same input renders the same output
+func (r *Renderer) IDs() *IDAllocator {
+	return r.ids
+}
+
+// TextMeasurer returns the measurer used for text
+func (r *Renderer) TextMeasurer() TextMeasurer {
+	if r.options.TextMeasurer != nil {
+		return r.options.TextMeasurer
+	}
+	return DefaultTextMeasurer()
+}
+
 // SetDefaultStyle sets the default style for rendered nodes
+// It is used when neither StyleFunc nor Theme is set
 func (r *Renderer) SetDefaultStyle(style Style) {
 	r.defaultStyle = style
 }
@@ -188,47 +512,31 @@ func (r *Renderer) SetDefaultStyle(style Style) {
 // RenderNodes renders multiple layout nodes at their computed positions
 // This is useful when you have a collection of already-positioned nodes
 func RenderNodes(nodes []*layout.Node, opts Options) string {
-	renderer := NewRenderer(opts)
-
-	var b strings.Builder
-
-	// Start SVG
-	b.WriteString(fmt.Sprintf(`<svg width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f"`,
-		opts.Width, opts.Height, opts.Width, opts.Height))
-
-	if opts.Namespace {
-		b.WriteString(` xmlns="http://www.w3.org/2000/svg"`)
-	}
-
-	b.WriteString(">")
-	b.WriteString("\n")
-
-	// Defs
-	b.WriteString("<defs>")
-	b.WriteString("\n")
+	return RenderLayers([]Layer{{Nodes: nodes}}, opts)
+}
 
-	if opts.StyleSheet != nil {
-		b.WriteString(opts.StyleSheet.ToSVG())
-		b.WriteString("\n")
-	}
+// nodeWriter streams rendered nodes to a writer
+type nodeWriter struct {
+	countingWriter
+	prefix string // Written before the next output, if there is any
+}
 
-	b.WriteString("</defs>")
-	b.WriteString("\n")
+// write writes s, after any pending prefix
+func (nw *nodeWriter) write(s string) {
+	nw.flushPrefix()
+	nw.writeString(s)
+}
 
-	// Background
-	if opts.BackgroundColor != "" {
-		b.WriteString(fmt.Sprintf(`<rect width="%.0f" height="%.0f" fill="%s"/>`,
-			opts.Width, opts.Height, opts.BackgroundColor))
-		b.WriteString("\n")
-	}
+// writeElement writes the markup of an element, after any pending prefix
+func (nw *nodeWriter) writeElement(e Element) {
+	nw.flushPrefix()
+	nw.countingWriter.writeElement(e)
+}
 
-	// Render each node
-	for _, node := range nodes {
-		content := renderer.renderNode(node, 0)
-		b.WriteString(content)
+func (nw *nodeWriter) flushPrefix() {
+	if nw.prefix != "" {
+		prefix := nw.prefix
+		nw.prefix = ""
+		nw.writeString(prefix)
 	}
-
-	b.WriteString("</svg>")
-
-	return b.String()
 }
@@ -0,0 +1,111 @@
+package svg
+
+import "strings"
+
+// unsafeElements are dropped with their content, as they can run script
+// or embed other documents
+var unsafeElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"handler":       true,
+	"listener":      true,
+}
+
+// animationElements can change attributes of other elements
+var animationElements = map[string]bool{
+	"set":              true,
+	"animate":          true,
+	"animatetransform": true,
+	"animatemotion":    true,
+}
+
+// Sanitize returns a copy of an untrusted document that is safe to embed
+//
+// Scripts, foreign content, event handlers (onclick, ...), links to script
+// URLs, stylesheets that can load script and animations that change those
+// attributes are removed. DOCTYPE declarations and processing instructions
+// other than the XML declaration are dropped, as are processing
+// instructions after the root element. Raw markup is parsed so it is
+// sanitized as well; Raw markup that does not parse is dropped. The input
+// document is not modified.
+func Sanitize(doc *Document) *Document {
+	out := &Document{Name: doc.Name, Attrs: SafeAttrs(doc.Attributes()...)}
+	for _, node := range doc.Prolog {
+		switch n := node.(type) {
+		case Comment:
+			out.Prolog = append(out.Prolog, n)
+		case Raw:
+			if strings.HasPrefix(string(n), "<?xml ") {
+				out.Prolog = append(out.Prolog, n)
+			}
+		}
+	}
+	for _, node := range doc.Epilog {
+		if n, ok := node.(Comment); ok {
+			out.Epilog = append(out.Epilog, n)
+		}
+	}
+	out.Add(sanitizeChildren("svg", doc.Children())...)
+	return out
+}
+
+// SanitizeString parses untrusted SVG markup and returns it sanitized
+func SanitizeString(s string) (string, error) {
+	doc, err := ParseString(s)
+	if err != nil {
+		return "", err
+	}
+	return Sanitize(doc).String(), nil
+}
+
+// sanitizeElement returns a safe copy of a node, or nil if it is dropped
+func sanitizeElement(parent string, e Element) []Element {
+	switch n := e.(type) {
+	case CharData:
+		if parent == "style" && unsafeCSS(string(n)) {
+			return nil
+		}
+		return []Element{n}
+
+	case Comment:
+		return []Element{n}
+
+	case Raw:
+		fragment, err := parseFragment(string(n))
+		if err != nil {
+			return nil
+		}
+		return sanitizeChildren(parent, fragment)
+	}
+
+	tag := e.Tag()
+	local := strings.ToLower(localName(tag))
+	if unsafeElements[local] {
+		return nil
+	}
+	attrs := e.Attributes()
+	if animationElements[local] {
+		target := strings.ToLower(localName(attrValue(attrs, "attributeName")))
+		if strings.HasPrefix(target, "on") || target == "href" || target == "style" {
+			return nil
+		}
+	}
+
+	return []Element{&GenericElement{
+		Name:     tag,
+		Attrs:    SafeAttrs(attrs...),
+		Elements: sanitizeChildren(local, e.Children()),
+	}}
+}
+
+// sanitizeChildren sanitizes a list of child nodes
+func sanitizeChildren(parent string, nodes []Element) []Element {
+	var out []Element
+	for _, child := range nodes {
+		out = append(out, sanitizeElement(parent, child)...)
+	}
+	return out
+}
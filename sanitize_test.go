@@ -0,0 +1,99 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestSanitizeString(t *testing.T) {
+	input := `<!DOCTYPE svg [<!ENTITY x "y">]>
+<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)">
+<script>alert(2)</script>
+<style>@import url(evil.css);</style>
+<style>.a { fill: red; }</style>
+<foreignObject><div>html</div></foreignObject>
+<a href="javascript:alert(3)"><rect width="10" height="10" onclick="alert(4)" fill="blue"/></a>
+<a xlink:href="https://example.com"><circle r="5" style="fill: green"/></a>
+<set attributeName="onclick" to="alert(5)"/>
+<animate attributeName="href" to="javascript:alert(6)"/>
+<animate attributeName="opacity" to="0.5"/>
+<rect style="background: url(javascript:alert(7))"/>
+</svg>`
+
+	result, err := SanitizeString(input)
+	if err != nil {
+		t.Fatalf("SanitizeString failed: %v", err)
+	}
+
+	for _, unsafe := range []string{"DOCTYPE", "onload", "<script", "alert", "@import", "foreignObject", "onclick", "javascript:", "<set"} {
+		if strings.Contains(result, unsafe) {
+			t.Errorf("Expected %q to be removed, got:\n%s", unsafe, result)
+		}
+	}
+	for _, safe := range []string{
+		`<style>.a { fill: red; }</style>`,
+		`<rect width="10" height="10" fill="blue"/>`,
+		`xlink:href="https://example.com"`,
+		`style="fill: green"`,
+		`attributeName="opacity"`,
+	} {
+		if !strings.Contains(result, safe) {
+			t.Errorf("Expected %q to be kept, got:\n%s", safe, result)
+		}
+	}
+}
+
+func TestSanitizeRaw(t *testing.T) {
+	doc := NewDocument(10, 10)
+	doc.Add(
+		Raw(`<g><script>alert(1)</script><rect width="1" height="1"/></g>`),
+		Raw(`<g onclick="alert(2)">`),
+	)
+
+	result := Sanitize(doc).String()
+	if strings.Contains(result, "alert") {
+		t.Errorf("Expected script in Raw markup to be removed, got:\n%s", result)
+	}
+	if !strings.Contains(result, `<g><rect width="1" height="1"/></g>`) {
+		t.Errorf("Expected safe Raw markup to be kept, got:\n%s", result)
+	}
+}
+
+func TestAttributeEscaping(t *testing.T) {
+	tests := []struct {
+		name     string
+		svg      string
+		expected string
+	}{
+		{"marker color", CircleMarker("m", `red" onload="alert(1)`), `fill="red&quot; onload=&quot;alert(1)"`},
+		{"overflow clip", overflowClipSVG(`x" onload="alert(1)-`), `clip-path="url(#x&quot; onload=&quot;alert(1)-clip-1)"`},
+		{"stylesheet", (&StyleSheet{Rules: []StyleRule{{Selector: ".a", Properties: map[string]string{"content": `"</style><script>"`}}}}).ToSVG(), `&lt;/style>&lt;script>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(tt.svg, tt.expected) {
+				t.Errorf("Expected %q in:\n%s", tt.expected, tt.svg)
+			}
+			if strings.Contains(tt.svg, `" onload="`) || strings.Contains(tt.svg, "</style><script>") {
+				t.Errorf("Value escaped its context:\n%s", tt.svg)
+			}
+		})
+	}
+}
+
+// overflowClipSVG renders a node whose children are clipped, using an ID prefix
+func overflowClipSVG(prefix string) string {
+	node := &layout.Node{
+		Rect:     layout.Rect{Width: 10, Height: 10},
+		Children: []*layout.Node{{Rect: layout.Rect{Width: 20, Height: 20}}},
+	}
+	opts := DefaultOptions()
+	opts.IDPrefix = prefix
+	opts.BoxFunc = func(n *layout.Node, depth int) (BoxStyle, bool) {
+		return BoxStyle{Overflow: OverflowHidden}, depth == 0
+	}
+	return RenderToSVG(node, opts)
+}
@@ -0,0 +1,86 @@
+package svg
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+// deepTree returns a chain of nested nodes
+func deepTree(depth int) *layout.Node {
+	root := &layout.Node{Rect: layout.Rect{Width: 100, Height: 100}}
+	node := root
+	for i := 0; i < depth; i++ {
+		child := &layout.Node{Rect: layout.Rect{X: float64(i), Y: float64(i), Width: 10, Height: 10}}
+		node.Children = []*layout.Node{child}
+		node = child
+	}
+	return root
+}
+
+func TestRenderTo(t *testing.T) {
+	root := deepTree(3)
+	opts := DefaultOptions()
+	opts.StyleSheet = &StyleSheet{Rules: []StyleRule{{Selector: ".a", Properties: map[string]string{"fill": "red"}}}}
+	opts.BoxFunc = func(n *layout.Node, depth int) (BoxStyle, bool) {
+		return BoxStyle{Overflow: OverflowHidden}, n == root
+	}
+
+	var b strings.Builder
+	n, err := NewRenderer(opts).RenderTo(&b, root)
+	if err != nil {
+		t.Fatalf("RenderTo failed: %v", err)
+	}
+	svg := b.String()
+	if n != int64(len(svg)) {
+		t.Errorf("Expected %d bytes written, got %d", len(svg), n)
+	}
+
+	// Stylesheets come first, definitions generated while rendering last
+	style := strings.Index(svg, "<style>")
+	content := strings.Index(svg, `<g clip-path="url(#clip-1)">`)
+	clip := strings.Index(svg, `<clipPath id="clip-1"`)
+	if style < 0 || content < style || clip < content {
+		t.Errorf("Expected stylesheet, content, then clip path:\n%s", svg)
+	}
+	if !strings.HasSuffix(svg, "</svg>") {
+		t.Errorf("Expected closing </svg>:\n%s", svg)
+	}
+
+	// The content is the same as Render's
+	rendered := NewRenderer(opts).Render(root)
+	_, renderedContent, _ := strings.Cut(rendered, "</defs>\n")
+	renderedContent = strings.TrimSuffix(renderedContent, "</svg>")
+	_, streamedContent, _ := strings.Cut(svg, "</defs>\n")
+	streamedContent = streamedContent[:strings.LastIndex(streamedContent, "<defs>")]
+	if streamedContent != renderedContent {
+		t.Errorf("Expected the same content as Render:\n%s\nGot:\n%s", renderedContent, streamedContent)
+	}
+}
+
+type failingWriter struct{ n int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n -= len(p); w.n < 0 {
+		return 0, errors.New("disk full")
+	}
+	return len(p), nil
+}
+
+func TestRenderToWriteError(t *testing.T) {
+	_, err := NewRenderer(DefaultOptions()).RenderTo(&failingWriter{n: 200}, deepTree(50))
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected the writer's error, got %v", err)
+	}
+}
+
+func BenchmarkRenderToDeepTree(b *testing.B) {
+	root := deepTree(500)
+	renderer := NewRenderer(DefaultOptions())
+	for b.Loop() {
+		renderer.RenderTo(io.Discard, root)
+	}
+}
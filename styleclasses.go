@@ -0,0 +1,112 @@
+package svg
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// StyleClasses deduplicates styles into generated CSS classes
+//
+// Each distinct Style is hashed and gets one class; elements then carry a
+// class attribute instead of repeating every presentation attribute. Class
+// names are derived from the style's content, so the same style always maps
+// to the same class, across renders and across SVGs inlined in one page.
+type StyleClasses struct {
+	// Prefix is prepended to generated class names (default "s-")
+	Prefix string
+
+	classes map[string]string // declaration key -> class name
+	names   map[string]string // class name -> declaration key
+	rules   []StyleRule
+}
+
+// NewStyleClasses creates a style class registry with the given class prefix
+func NewStyleClasses(prefix string) *StyleClasses {
+	return &StyleClasses{Prefix: prefix}
+}
+
+// Class registers a style and returns a Style that references its class
+//
+// Presentation properties move into the class. Class and ClipPath are kept
+// on the returned Style, since they are specific to the element.
+func (c *StyleClasses) Class(s Style) Style {
+	decls := styleDeclarations(s)
+	if len(decls) == 0 {
+		return s
+	}
+
+	class := c.className(decls)
+	out := Style{Class: class, ClipPath: s.ClipPath}
+	if s.Class != "" {
+		out.Class = s.Class + " " + class
+	}
+	return out
+}
+
+// Len returns the number of generated classes
+func (c *StyleClasses) Len() int {
+	return len(c.rules)
+}
+
+// Rules returns one rule per generated class, in order of first use
+func (c *StyleClasses) Rules() []StyleRule {
+	return c.rules
+}
+
+// StyleSheet returns a stylesheet containing the generated classes
+func (c *StyleClasses) StyleSheet() *StyleSheet {
+	return &StyleSheet{Rules: c.rules}
+}
+
+// className returns the class for a declaration list, registering it if needed
+func (c *StyleClasses) className(decls []Declaration) string {
+	var key strings.Builder
+	for _, decl := range decls {
+		key.WriteString(decl.Property)
+		key.WriteString(":")
+		key.WriteString(decl.Value)
+		key.WriteString(";")
+	}
+
+	if class, ok := c.classes[key.String()]; ok {
+		return class
+	}
+	if c.classes == nil {
+		c.classes = make(map[string]string)
+		c.names = make(map[string]string)
+	}
+
+	prefix := c.Prefix
+	if prefix == "" {
+		prefix = "s-"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key.String()))
+	class := prefix + strconv.FormatUint(uint64(h.Sum32()), 36)
+
+	// Hash collisions between different styles get a numeric suffix
+	for n := 2; c.names[class] != ""; n++ {
+		class = prefix + strconv.FormatUint(uint64(h.Sum32()), 36) + "-" + strconv.Itoa(n)
+	}
+
+	c.classes[key.String()] = class
+	c.names[class] = key.String()
+	// :where() keeps the specificity of presentation attributes, zero, so
+	// any rule of a user stylesheet still wins
+	c.rules = append(c.rules, Rule(":where(."+class+")", decls...))
+	return class
+}
+
+// styleDeclarations returns the CSS declarations for a style's presentation
+// properties; class and clip-path are element-specific and left out
+func styleDeclarations(s Style) []Declaration {
+	var decls []Declaration
+	for _, attr := range styleAttrs(s) {
+		if attr.Name == "class" || attr.Name == "clip-path" {
+			continue
+		}
+		decls = append(decls, Decl(attr.Name, attr.Value))
+	}
+	return decls
+}
@@ -0,0 +1,87 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestStyleClasses(t *testing.T) {
+	classes := NewStyleClasses("")
+
+	a := classes.Class(Style{Fill: "red", Stroke: "#333", StrokeWidth: 1})
+	b := classes.Class(Style{Fill: "red", Stroke: "#333", StrokeWidth: 1, ClipPath: "url(#c)"})
+	c := classes.Class(Style{Fill: "blue", Class: "bar"})
+
+	if a.Class != b.Class {
+		t.Errorf("identical styles got different classes: %q and %q", a.Class, b.Class)
+	}
+	if !strings.HasPrefix(a.Class, "s-") {
+		t.Errorf("class %q should use the default prefix", a.Class)
+	}
+	if a.Fill != "" || a.Stroke != "" || a.StrokeWidth != 0 {
+		t.Errorf("presentation properties should move into the class: %+v", a)
+	}
+	if b.ClipPath != "url(#c)" {
+		t.Errorf("clip path should stay on the element, got %q", b.ClipPath)
+	}
+	if !strings.HasPrefix(c.Class, "bar s-") {
+		t.Errorf("existing class should be kept, got %q", c.Class)
+	}
+	if classes.Len() != 2 {
+		t.Errorf("expected 2 classes, got %d", classes.Len())
+	}
+
+	css := classes.StyleSheet().CSS()
+	want := ":where(." + a.Class + ") {\n    fill: red;\n    stroke: #333;\n    stroke-width: 1.00;\n}"
+	if !strings.Contains(css, want) {
+		t.Errorf("expected %q in:\n%s", want, css)
+	}
+
+	// Class names depend only on the style
+	other := NewStyleClasses("")
+	if got := other.Class(Style{Fill: "blue"}).Class; got != strings.TrimPrefix(c.Class, "bar ") {
+		t.Errorf("class names should be deterministic, got %q and %q", got, c.Class)
+	}
+
+	if empty := classes.Class(Style{Class: "plain"}); empty.Class != "plain" {
+		t.Errorf("style without properties should be unchanged, got %+v", empty)
+	}
+}
+
+func TestRenderDeduplicateStyles(t *testing.T) {
+	root := &layout.Node{Rect: layout.Rect{Width: 100, Height: 100}}
+	for i := 0; i < 10; i++ {
+		root.Children = append(root.Children, &layout.Node{
+			Rect: layout.Rect{X: float64(i * 10), Width: 10, Height: 10},
+		})
+	}
+
+	opts := DefaultOptions()
+	opts.DeduplicateStyles = true
+	opts.StyleClassPrefix = "n-"
+	output := RenderToSVG(root, opts)
+
+	if strings.Contains(output, `fill="#e0e0e0"`) {
+		t.Error("fill should not be repeated as an attribute")
+	}
+	if got := strings.Count(output, "fill: #e0e0e0;"); got != 1 {
+		t.Errorf("expected one generated rule, got %d", got)
+	}
+	if got := strings.Count(output, `class="n-`); got != 11 {
+		t.Errorf("expected 11 elements with a generated class, got %d", got)
+	}
+
+	// Generated rules yield to the user stylesheet, like attributes would
+	generated := strings.Index(output, ":where(.n-")
+	user := strings.Index(output, "<style>\n    .sans")
+	if generated < 0 || user < 0 || generated > user {
+		t.Errorf("expected generated rules before the user stylesheet:\n%s", output)
+	}
+
+	nodes := RenderNodes(root.Children, opts)
+	if !strings.Contains(nodes, "fill: #e0e0e0;") || strings.Contains(nodes, `fill="#e0e0e0"`) {
+		t.Error("RenderNodes should emit generated classes")
+	}
+}
@@ -1,99 +1,166 @@
 package svg
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // StyleSheet represents a collection of CSS styles for SVG rendering
 type StyleSheet struct {
 	Rules []StyleRule
 }
 
-// StyleRule represents a single CSS rule
+// StyleRule represents a single CSS rule or at-rule
+//
+// For a qualified rule, Selector is the selector (e.g. ".sans").
+// For an at-rule, Selector is the full prelude (e.g. "@media (max-width: 600px)",
+// "@font-face" or "@keyframes spin"); block at-rules hold either Declarations
+// (@font-face) or nested Rules (@media, @keyframes). Statement at-rules such as
+// `@import url(theme.css);` set Statement.
 type StyleRule struct {
-	Selector   string
+	Selector string
+
+	// Statement writes an at-rule without a block, ending it with ';'
+	// Block at-rules keep their braces even when they are empty
+	Statement bool
+
+	// Declarations are written in order
+	Declarations []Declaration
+
+	// Properties are written after Declarations, sorted by property name
+	// Prefer Declarations when the order of properties matters
 	Properties map[string]string
+
+	// Rules are nested rules of a block at-rule
+	Rules []StyleRule
+}
+
+// Declaration is a single CSS property declaration
+type Declaration struct {
+	Property string
+	Value    string
+}
+
+// Decl creates a CSS declaration
+func Decl(property, value string) Declaration {
+	return Declaration{Property: property, Value: value}
+}
+
+// Rule creates a CSS rule with ordered declarations
+func Rule(selector string, declarations ...Declaration) StyleRule {
+	return StyleRule{Selector: selector, Declarations: declarations}
+}
+
+// IsAtRule reports whether the rule is an at-rule
+func (r StyleRule) IsAtRule() bool {
+	return strings.HasPrefix(r.Selector, "@")
+}
+
+// Get returns the value of a property and whether it is set
+func (r StyleRule) Get(property string) (string, bool) {
+	for i := len(r.Declarations) - 1; i >= 0; i-- {
+		if r.Declarations[i].Property == property {
+			return r.Declarations[i].Value, true
+		}
+	}
+	value, ok := r.Properties[property]
+	return value, ok
+}
+
+// Set sets a property, replacing an existing declaration in place
+// or appending a new one
+func (r *StyleRule) Set(property, value string) {
+	for i := range r.Declarations {
+		if r.Declarations[i].Property == property {
+			r.Declarations[i].Value = value
+			return
+		}
+	}
+	r.Declarations = append(r.Declarations, Declaration{Property: property, Value: value})
+}
+
+// OrderedDeclarations returns the rule's Declarations followed by its
+// Properties sorted by name
+func (r StyleRule) OrderedDeclarations() []Declaration {
+	if len(r.Properties) == 0 {
+		return r.Declarations
+	}
+
+	names := make([]string, 0, len(r.Properties))
+	for name := range r.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	decls := make([]Declaration, 0, len(r.Declarations)+len(names))
+	decls = append(decls, r.Declarations...)
+	for _, name := range names {
+		decls = append(decls, Declaration{Property: name, Value: r.Properties[name]})
+	}
+	return decls
 }
 
 // DefaultStyleSheet returns a sensible default stylesheet for SVG rendering
 func DefaultStyleSheet() *StyleSheet {
 	return &StyleSheet{
 		Rules: []StyleRule{
-			{
-				Selector: ".sans",
-				Properties: map[string]string{
-					"font-family": `-apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif, "Apple Color Emoji", "Segoe UI Emoji"`,
-				},
-			},
-			{
-				Selector: ".mono",
-				Properties: map[string]string{
-					"font-family":    `ui-monospace, SFMono-Regular, "SF Mono", Menlo, Consolas, "Liberation Mono", monospace`,
-					"font-size":      "12px",
-					"letter-spacing": "-0.5px",
-				},
-			},
-			{
-				Selector: ".bold",
-				Properties: map[string]string{
-					"font-weight": "500",
-				},
-			},
-			{
-				Selector: ".medium",
-				Properties: map[string]string{
-					"font-size": "16px",
-				},
-			},
-			{
-				Selector: ".small",
-				Properties: map[string]string{
-					"font-size": "14px",
-				},
-			},
-			{
-				Selector: ".smaller",
-				Properties: map[string]string{
-					"font-size": "12px",
-				},
-			},
-			{
-				Selector: ".pre",
-				Properties: map[string]string{
-					"white-space": "pre",
-				},
-			},
-			{
-				Selector: ".glow",
-				Properties: map[string]string{
-					"paint-order": "stroke",
-				},
-			},
+			Rule(".sans", Decl("font-family", `-apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif, "Apple Color Emoji", "Segoe UI Emoji"`)),
+			Rule(".mono",
+				Decl("font-family", `ui-monospace, SFMono-Regular, "SF Mono", Menlo, Consolas, "Liberation Mono", monospace`),
+				Decl("font-size", "12px"),
+				Decl("letter-spacing", "-0.5px"),
+			),
+			Rule(".bold", Decl("font-weight", "500")),
+			Rule(".medium", Decl("font-size", "16px")),
+			Rule(".small", Decl("font-size", "14px")),
+			Rule(".smaller", Decl("font-size", "12px")),
+			Rule(".pre", Decl("white-space", "pre")),
+			Rule(".glow", Decl("paint-order", "stroke")),
 		},
 	}
 }
 
 // ToSVG converts the stylesheet to SVG <style> element
 func (ss *StyleSheet) ToSVG() string {
+	var css strings.Builder
+	writeRules(&css, ss.Rules, "\n    ")
+
+	// Escape markup characters, so CSS text cannot close the <style> element
+	escaped := strings.NewReplacer("&", "&amp;", "<", "&lt;").Replace(css.String())
+	return "<style>" + escaped + "\n</style>"
+}
+
+// CSS returns the stylesheet as CSS text
+func (ss *StyleSheet) CSS() string {
 	var b strings.Builder
-	b.WriteString("<style>")
+	writeRules(&b, ss.Rules, "\n")
+	return strings.TrimPrefix(b.String(), "\n")
+}
 
-	for _, rule := range ss.Rules {
-		b.WriteString("\n    ")
+// writeRules writes rules, starting each one with indent
+func writeRules(b *strings.Builder, rules []StyleRule, indent string) {
+	for _, rule := range rules {
+		b.WriteString(indent)
 		b.WriteString(rule.Selector)
-		b.WriteString(" {")
 
-		for prop, value := range rule.Properties {
-			b.WriteString("\n        ")
-			b.WriteString(prop)
-			b.WriteString(": ")
-			b.WriteString(value)
+		if rule.Statement && rule.IsAtRule() {
 			b.WriteString(";")
+			continue
 		}
 
-		b.WriteString("\n    }")
+		b.WriteString(" {")
+		for _, decl := range rule.OrderedDeclarations() {
+			b.WriteString(indent)
+			b.WriteString("    ")
+			b.WriteString(decl.Property)
+			b.WriteString(": ")
+			b.WriteString(decl.Value)
+			b.WriteString(";")
+		}
+		writeRules(b, rule.Rules, indent+"    ")
+		b.WriteString(indent)
+		b.WriteString("}")
 	}
-
-	b.WriteString("\n</style>")
-	return b.String()
 }
 
 // AddRule adds a custom CSS rule to the stylesheet
@@ -103,3 +170,24 @@ func (ss *StyleSheet) AddRule(selector string, properties map[string]string) {
 		Properties: properties,
 	})
 }
+
+// Add appends rules to the stylesheet
+func (ss *StyleSheet) Add(rules ...StyleRule) *StyleSheet {
+	ss.Rules = append(ss.Rules, rules...)
+	return ss
+}
+
+// AddMedia adds an @media block containing the given rules
+func (ss *StyleSheet) AddMedia(query string, rules ...StyleRule) *StyleSheet {
+	return ss.Add(StyleRule{Selector: "@media " + query, Rules: rules})
+}
+
+// AddFontFace adds an @font-face rule
+func (ss *StyleSheet) AddFontFace(declarations ...Declaration) *StyleSheet {
+	return ss.Add(StyleRule{Selector: "@font-face", Declarations: declarations})
+}
+
+// AddKeyframes adds an @keyframes rule; frames use selectors such as "from", "50%" or "to"
+func (ss *StyleSheet) AddKeyframes(name string, frames ...StyleRule) *StyleSheet {
+	return ss.Add(StyleRule{Selector: "@keyframes " + name, Rules: frames})
+}
@@ -0,0 +1,262 @@
+package svg
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// WhiteSpace controls how a text block handles spaces and line breaks
+// The modes follow the CSS white-space property
+type WhiteSpace string
+
+const (
+	WhiteSpaceNormal  WhiteSpace = "normal"   // Collapse spaces and newlines, wrap
+	WhiteSpaceNoWrap  WhiteSpace = "nowrap"   // Collapse spaces and newlines, never wrap
+	WhiteSpacePre     WhiteSpace = "pre"      // Keep spaces and newlines, never wrap
+	WhiteSpacePreWrap WhiteSpace = "pre-wrap" // Keep spaces and newlines, wrap
+	WhiteSpacePreLine WhiteSpace = "pre-line" // Collapse spaces, keep newlines, wrap
+)
+
+// DefaultEllipsis marks text truncated by TextBlockOptions.MaxLines
+const DefaultEllipsis = "…"
+
+// TextBlockOptions configures a wrapped text block
+type TextBlockOptions struct {
+	// Align positions lines within the block width
+	// If empty, the style's TextAnchor is used
+	Align TextAnchor
+
+	// LineHeight is the distance between baselines as a multiple of the
+	// font size; 0 uses the font's own line height
+	LineHeight float64
+
+	// MaxLines limits the number of lines; 0 means unlimited
+	MaxLines int
+
+	// Ellipsis is appended to the last line when text is cut by MaxLines
+	// If empty, DefaultEllipsis is used
+	Ellipsis string
+
+	// WhiteSpace selects how spaces and newlines are handled (default normal)
+	WhiteSpace WhiteSpace
+
+	// Measurer measures text for wrapping (default DefaultTextMeasurer)
+	Measurer TextMeasurer
+}
+
+// DefaultTextBlockOptions returns options for normal wrapping text
+func DefaultTextBlockOptions() TextBlockOptions {
+	return TextBlockOptions{
+		LineHeight: 1.2,
+		Ellipsis:   DefaultEllipsis,
+		WhiteSpace: WhiteSpaceNormal,
+	}
+}
+
+// TextBlockLine is a single line of a laid out text block
+type TextBlockLine struct {
+	Text     string
+	Width    float64
+	X        float64 // Anchor position relative to the block's left edge
+	Baseline float64 // Baseline position relative to the block's top edge
+}
+
+// TextBlockLayout is the result of laying out a text block
+type TextBlockLayout struct {
+	Lines      []TextBlockLine
+	Width      float64 // Width of the widest line
+	Height     float64 // Number of lines times the line height
+	LineHeight float64
+	Truncated  bool // Text was cut to fit MaxLines
+}
+
+// LayoutTextBlock wraps content to maxWidth and positions its lines
+//
+// A maxWidth of 0 or less disables wrapping. Words wider than maxWidth are
+// broken between characters.
+func LayoutTextBlock(content string, maxWidth float64, style Style, opts TextBlockOptions) TextBlockLayout {
+	measurer := opts.Measurer
+	if measurer == nil {
+		measurer = DefaultTextMeasurer()
+	}
+	measure := func(s string) float64 { return measurer.Measure(s, style).Width }
+
+	metrics := measurer.Measure("", style)
+	lineHeight := metrics.LineHeight
+	if opts.LineHeight > 0 {
+		lineHeight = opts.LineHeight * FontSizePx(style)
+	}
+
+	whiteSpace := opts.WhiteSpace
+	if whiteSpace == "" {
+		whiteSpace = WhiteSpaceNormal
+	}
+	wrapWidth := maxWidth
+	if whiteSpace == WhiteSpaceNoWrap || whiteSpace == WhiteSpacePre {
+		wrapWidth = 0
+	}
+
+	var lines []string
+	for _, paragraph := range splitParagraphs(content, whiteSpace) {
+		lines = append(lines, wrapLine(paragraph, wrapWidth, measure)...)
+	}
+
+	var truncated bool
+	if opts.MaxLines > 0 && len(lines) > opts.MaxLines {
+		ellipsis := opts.Ellipsis
+		if ellipsis == "" {
+			ellipsis = DefaultEllipsis
+		}
+		lines = lines[:opts.MaxLines]
+		lines[len(lines)-1] = ellipsize(lines[len(lines)-1], ellipsis, maxWidth, measure)
+		truncated = true
+	}
+
+	align := opts.Align
+	if align == "" {
+		align = style.TextAnchor
+	}
+
+	// The baseline sits half the leading below the top of each line box, as in CSS
+	halfLeading := (lineHeight - metrics.Ascent - metrics.Descent) / 2
+
+	out := TextBlockLayout{LineHeight: lineHeight, Truncated: truncated}
+	for i, text := range lines {
+		line := TextBlockLine{
+			Text:     text,
+			Width:    measure(text),
+			Baseline: float64(i)*lineHeight + halfLeading + metrics.Ascent,
+		}
+		if maxWidth > 0 {
+			switch align {
+			case TextAnchorMiddle:
+				line.X = maxWidth / 2
+			case TextAnchorEnd:
+				line.X = maxWidth
+			}
+		}
+		if line.Width > out.Width {
+			out.Width = line.Width
+		}
+		out.Lines = append(out.Lines, line)
+	}
+	out.Height = float64(len(lines)) * lineHeight
+
+	return out
+}
+
+// NewTextBlock creates a <text> element with one <tspan> per line
+// x and y are the top-left corner of the block
+func NewTextBlock(content string, x, y, maxWidth float64, style Style, opts TextBlockOptions) *TextElement {
+	block := LayoutTextBlock(content, maxWidth, style, opts)
+
+	if opts.Align != "" {
+		style.TextAnchor = opts.Align
+	}
+
+	text := &TextElement{Style: style}
+	if len(block.Lines) > 0 {
+		text.X = x + block.Lines[0].X
+		text.Y = y + block.Lines[0].Baseline
+	}
+	switch opts.WhiteSpace {
+	case WhiteSpacePre, WhiteSpacePreWrap:
+		text.Attrs = append(text.Attrs, Attr{"xml:space", "preserve"})
+	}
+
+	for _, line := range block.Lines {
+		if line.Text == "" {
+			continue // Empty tspans do not advance the position, so blank lines are skipped
+		}
+		text.Spans = append(text.Spans, &TSpanElement{
+			Content: line.Text,
+			Attrs: []Attr{
+				{"x", formatFloat(x + line.X)},
+				{"y", formatFloat(y + line.Baseline)},
+			},
+		})
+	}
+	return text
+}
+
+// TextBlock renders word-wrapped text as a <text> element with <tspan> lines
+// x and y are the top-left corner of the block
+func TextBlock(content string, x, y, maxWidth float64, style Style, opts TextBlockOptions) string {
+	return Serialize(NewTextBlock(content, x, y, maxWidth, style, opts))
+}
+
+// splitParagraphs splits content at forced line breaks and applies
+// white-space collapsing
+func splitParagraphs(content string, whiteSpace WhiteSpace) []string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	switch whiteSpace {
+	case WhiteSpacePre, WhiteSpacePreWrap:
+		return strings.Split(strings.ReplaceAll(content, "\t", "    "), "\n")
+	case WhiteSpacePreLine:
+		paragraphs := strings.Split(content, "\n")
+		for i, p := range paragraphs {
+			paragraphs[i] = collapseWhitespace(p)
+		}
+		return paragraphs
+	}
+	return []string{collapseWhitespace(content)}
+}
+
+// wrapLine greedily breaks a paragraph into lines no wider than maxWidth
+// Spaces between words are kept, so preserved runs of spaces survive
+func wrapLine(paragraph string, maxWidth float64, measure func(string) float64) []string {
+	if maxWidth <= 0 || measure(paragraph) <= maxWidth {
+		return []string{paragraph}
+	}
+
+	var lines []string
+	line := ""
+	for i, word := range strings.Split(paragraph, " ") {
+		candidate := word
+		if i > 0 {
+			candidate = line + " " + word
+		}
+		if measure(candidate) <= maxWidth {
+			line = candidate
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+		// Words wider than the block are broken between characters
+		for measure(word) > maxWidth {
+			head, tail := breakWord(word, maxWidth, measure)
+			lines = append(lines, head)
+			word = tail
+		}
+		line = word
+	}
+	return append(lines, line)
+}
+
+// breakWord splits a word after the most characters that fit in maxWidth
+// At least one character is always taken, so the split makes progress
+func breakWord(word string, maxWidth float64, measure func(string) float64) (string, string) {
+	_, size := utf8.DecodeRuneInString(word)
+	end := size
+	for end < len(word) {
+		_, size := utf8.DecodeRuneInString(word[end:])
+		if measure(word[:end+size]) > maxWidth {
+			break
+		}
+		end += size
+	}
+	return word[:end], word[end:]
+}
+
+// ellipsize appends an ellipsis, dropping characters until the line fits
+func ellipsize(line, ellipsis string, maxWidth float64, measure func(string) float64) string {
+	line = strings.TrimRight(line, " ")
+	for maxWidth > 0 && line != "" && measure(line+ellipsis) > maxWidth {
+		_, size := utf8.DecodeLastRuneInString(line)
+		line = strings.TrimRight(line[:len(line)-size], " ")
+	}
+	return line + ellipsis
+}
@@ -0,0 +1,151 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/units"
+)
+
+// fixedMeasurer measures every character as 10px wide
+type fixedMeasurer struct{}
+
+func (fixedMeasurer) Measure(text string, style Style) TextMetrics {
+	return TextMetrics{
+		Width:      float64(len([]rune(text))) * 10,
+		Ascent:     8,
+		Descent:    2,
+		LineHeight: 12,
+	}
+}
+
+func TestLayoutTextBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		maxWidth float64
+		opts     TextBlockOptions
+		expected []string
+	}{
+		{
+			name:     "wraps at words",
+			content:  "the quick brown fox",
+			maxWidth: 100,
+			expected: []string{"the quick", "brown fox"},
+		},
+		{
+			name:     "collapses whitespace",
+			content:  "  a \n\t b  ",
+			maxWidth: 100,
+			expected: []string{"a b"},
+		},
+		{
+			name:     "breaks long words",
+			content:  "abcdefghijkl xy",
+			maxWidth: 50,
+			expected: []string{"abcde", "fghij", "kl xy"},
+		},
+		{
+			name:     "no wrapping without width",
+			content:  "the quick brown fox",
+			expected: []string{"the quick brown fox"},
+		},
+		{
+			name:     "nowrap",
+			content:  "the quick\nbrown fox",
+			maxWidth: 50,
+			opts:     TextBlockOptions{WhiteSpace: WhiteSpaceNoWrap},
+			expected: []string{"the quick brown fox"},
+		},
+		{
+			name:     "pre keeps spaces and newlines",
+			content:  "a  b\n\nc",
+			maxWidth: 10,
+			opts:     TextBlockOptions{WhiteSpace: WhiteSpacePre},
+			expected: []string{"a  b", "", "c"},
+		},
+		{
+			name:     "pre-wrap",
+			content:  "aa  bb cc\ndd",
+			maxWidth: 60,
+			opts:     TextBlockOptions{WhiteSpace: WhiteSpacePreWrap},
+			expected: []string{"aa  bb", "cc", "dd"},
+		},
+		{
+			name:     "pre-line",
+			content:  "a   b\nc",
+			maxWidth: 100,
+			opts:     TextBlockOptions{WhiteSpace: WhiteSpacePreLine},
+			expected: []string{"a b", "c"},
+		},
+		{
+			name:     "max lines with ellipsis",
+			content:  "one two three four five",
+			maxWidth: 90,
+			opts:     TextBlockOptions{MaxLines: 2},
+			expected: []string{"one two", "three…"},
+		},
+		{
+			name:     "ellipsis is made to fit",
+			content:  "aaaaa bbbbb ccccc",
+			maxWidth: 50,
+			opts:     TextBlockOptions{MaxLines: 1, Ellipsis: "..."},
+			expected: []string{"aa..."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Measurer = fixedMeasurer{}
+			block := LayoutTextBlock(tt.content, tt.maxWidth, Style{}, tt.opts)
+
+			var got []string
+			for _, line := range block.Lines {
+				got = append(got, line.Text)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("lines = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLayoutTextBlockPositions(t *testing.T) {
+	opts := TextBlockOptions{Measurer: fixedMeasurer{}, Align: TextAnchorMiddle, LineHeight: 1.5}
+	block := LayoutTextBlock("aa bb", 20, Style{FontSize: units.Px(10)}, opts)
+
+	if block.LineHeight != 15 || block.Height != 30 || block.Width != 20 {
+		t.Errorf("unexpected block size: %+v", block)
+	}
+	// Half leading is (15 - 10) / 2, plus the ascent
+	if block.Lines[0].Baseline != 10.5 || block.Lines[1].Baseline != 25.5 {
+		t.Errorf("unexpected baselines: %+v", block.Lines)
+	}
+	if block.Lines[0].X != 10 {
+		t.Errorf("centered lines should anchor at half the width, got %v", block.Lines[0].X)
+	}
+}
+
+func TestTextBlock(t *testing.T) {
+	opts := DefaultTextBlockOptions()
+	opts.Measurer = fixedMeasurer{}
+	opts.Align = TextAnchorEnd
+	output := TextBlock("hello big world", 10, 20, 100, Style{FontSize: units.Px(10)}, opts)
+
+	tests := []string{
+		`text-anchor="end"`,
+		`<tspan x="110.00" y="29.00">hello big</tspan>`,
+		`<tspan x="110.00" y="41.00">world</tspan>`,
+	}
+
+	for _, want := range tests {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in %s", want, output)
+		}
+	}
+
+	pre := TextBlock("a  b", 0, 0, 0, Style{}, TextBlockOptions{WhiteSpace: WhiteSpacePre, Measurer: fixedMeasurer{}})
+	if !strings.Contains(pre, `xml:space="preserve"`) {
+		t.Errorf("pre text should preserve spaces: %s", pre)
+	}
+}
@@ -0,0 +1,245 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/SCKelemen/units"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// DefaultFontSize is the font size in px used when a Style has none
+const DefaultFontSize = 16.0
+
+// TextMetrics describes the size of a run of text in px
+type TextMetrics struct {
+	Width      float64 // Advance width of the text
+	Ascent     float64 // Distance from the baseline to the top of the line box
+	Descent    float64 // Distance from the baseline to the bottom of the line box (positive)
+	LineHeight float64 // Recommended distance between consecutive baselines
+}
+
+// TextMeasurer measures text rendered with a Style
+// The style's FontFamily, FontSize, FontWeight and FontStyle are used
+type TextMeasurer interface {
+	Measure(text string, style Style) TextMetrics
+}
+
+// FontMeasurer measures text using TrueType and OpenType font metrics
+//
+// Fonts are registered per family, weight and style. A family list such as
+// `"Inter", Arial, sans-serif` resolves to the first registered family; the
+// generic monospace families resolve to Go Mono, and anything else falls
+// back to the Go fonts, which are always registered.
+type FontMeasurer struct {
+	mu    sync.RWMutex
+	fonts map[fontKey]*sfnt.Font
+}
+
+// fontKey identifies a registered font face
+type fontKey struct {
+	family string // lower case
+	bold   bool
+	italic bool
+}
+
+// Family names of the built-in Go fonts
+const (
+	goFontFamily     = "go"
+	goMonoFontFamily = "go mono"
+)
+
+// NewFontMeasurer creates a font measurer with the Go fonts registered
+func NewFontMeasurer() *FontMeasurer {
+	m := &FontMeasurer{fonts: make(map[fontKey]*sfnt.Font)}
+	builtin := []struct {
+		family       string
+		bold, italic bool
+		ttf          []byte
+	}{
+		{goFontFamily, false, false, goregular.TTF},
+		{goFontFamily, true, false, gobold.TTF},
+		{goFontFamily, false, true, goitalic.TTF},
+		{goFontFamily, true, true, gobolditalic.TTF},
+		{goMonoFontFamily, false, false, gomono.TTF},
+		{goMonoFontFamily, true, false, gomonobold.TTF},
+		{goMonoFontFamily, false, true, gomonoitalic.TTF},
+		{goMonoFontFamily, true, true, gomonobolditalic.TTF},
+	}
+	for _, b := range builtin {
+		f, err := sfnt.Parse(b.ttf)
+		if err != nil {
+			panic(fmt.Sprintf("svg: invalid built-in font: %v", err))
+		}
+		m.fonts[fontKey{b.family, b.bold, b.italic}] = f
+	}
+	return m
+}
+
+var defaultTextMeasurer = sync.OnceValue(NewFontMeasurer)
+
+// DefaultTextMeasurer returns a shared FontMeasurer with the Go fonts
+func DefaultTextMeasurer() *FontMeasurer {
+	return defaultTextMeasurer()
+}
+
+// RegisterFont registers a TrueType or OpenType font for a family, weight and style
+// Weights of 600 and above (and "bold"/"bolder") select the bold face
+func (m *FontMeasurer) RegisterFont(family string, weight FontWeight, style FontStyle, data []byte) error {
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse font %q: %w", family, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fonts == nil {
+		m.fonts = make(map[fontKey]*sfnt.Font)
+	}
+	m.fonts[fontKey{normalizeFamily(family), isBold(weight), isItalic(style)}] = f
+	return nil
+}
+
+// Measure returns the metrics of text set in style
+func (m *FontMeasurer) Measure(text string, style Style) TextMetrics {
+	f := m.font(style)
+	if f == nil {
+		return TextMetrics{}
+	}
+
+	var buf sfnt.Buffer
+	ppem := fixed.Int26_6(FontSizePx(style)*64 + 0.5)
+
+	var metrics TextMetrics
+	if fm, err := f.Metrics(&buf, ppem, font.HintingNone); err == nil {
+		metrics.Ascent = fixedToFloat(fm.Ascent)
+		metrics.Descent = fixedToFloat(fm.Descent)
+		metrics.LineHeight = fixedToFloat(fm.Height)
+	}
+
+	var width fixed.Int26_6
+	var prev sfnt.GlyphIndex
+	for i, r := range text {
+		gid, err := f.GlyphIndex(&buf, r)
+		if err != nil {
+			continue
+		}
+		if i > 0 {
+			if kern, err := f.Kern(&buf, prev, gid, ppem, font.HintingNone); err == nil {
+				width += kern
+			}
+		}
+		if advance, err := f.GlyphAdvance(&buf, gid, ppem, font.HintingNone); err == nil {
+			width += advance
+		}
+		prev = gid
+	}
+	metrics.Width = fixedToFloat(width)
+
+	return metrics
+}
+
+// Face returns a font.Face for drawing text set in style
+// The face is sized in px and is not safe for concurrent use
+func (m *FontMeasurer) Face(style Style) (font.Face, error) {
+	f := m.font(style)
+	if f == nil {
+		return nil, fmt.Errorf("no font registered for %q", style.FontFamily)
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    FontSizePx(style),
+		DPI:     72, // 1pt == 1px
+		Hinting: font.HintingNone,
+	})
+}
+
+// font returns the registered font that best matches a style
+func (m *FontMeasurer) font(style Style) *sfnt.Font {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	bold, italic := isBold(style.FontWeight), isItalic(style.FontStyle)
+	for _, family := range append(splitFamilies(style.FontFamily), goFontFamily) {
+		switch family {
+		case "monospace", "ui-monospace":
+			family = goMonoFontFamily
+		}
+		for _, key := range []fontKey{
+			{family, bold, italic},
+			{family, bold, false},
+			{family, false, italic},
+			{family, false, false},
+		} {
+			if f := m.fonts[key]; f != nil {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// FontSizePx returns a style's font size in px
+// Relative sizes are resolved against DefaultFontSize
+func FontSizePx(style Style) float64 {
+	size := style.FontSize
+	if size.Value == 0 {
+		return DefaultFontSize
+	}
+	if size.Unit == "" {
+		return size.Value
+	}
+	px, err := size.Resolve(&units.Context{
+		FontSize:     DefaultFontSize,
+		RootFontSize: DefaultFontSize,
+	})
+	if err != nil || px.Value <= 0 {
+		return DefaultFontSize
+	}
+	return px.Value
+}
+
+// splitFamilies splits a CSS font-family list into normalized names
+func splitFamilies(families string) []string {
+	var names []string
+	for _, name := range strings.Split(families, ",") {
+		if name = normalizeFamily(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// normalizeFamily unquotes and lower-cases a font family name
+func normalizeFamily(family string) string {
+	family = strings.TrimSpace(family)
+	family = strings.Trim(family, `"'`)
+	return strings.ToLower(strings.TrimSpace(family))
+}
+
+func isBold(weight FontWeight) bool {
+	switch weight {
+	case FontWeightBold, FontWeightBolder, FontWeight600, FontWeight700, FontWeight800, FontWeight900:
+		return true
+	}
+	return false
+}
+
+func isItalic(style FontStyle) bool {
+	return style == FontStyleItalic || style == FontStyleOblique
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
@@ -0,0 +1,137 @@
+package svg
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/SCKelemen/units"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+func TestFontMeasurer(t *testing.T) {
+	m := DefaultTextMeasurer()
+
+	regular := m.Measure("Hello", Style{FontSize: units.Px(16)})
+	if regular.Width <= 0 || regular.Ascent <= 0 || regular.Descent <= 0 {
+		t.Fatalf("unexpected metrics: %+v", regular)
+	}
+	if regular.LineHeight < regular.Ascent+regular.Descent {
+		t.Errorf("line height %v is smaller than ascent + descent", regular.LineHeight)
+	}
+
+	double := m.Measure("Hello", Style{FontSize: units.Px(32)})
+	if math.Abs(double.Width-2*regular.Width) > 0.1 {
+		t.Errorf("width at 32px = %v, want about %v", double.Width, 2*regular.Width)
+	}
+
+	if got := m.Measure("Hello", Style{}); got.Width != regular.Width {
+		t.Errorf("default font size should be %vpx", DefaultFontSize)
+	}
+	if got := m.Measure("Hello", Style{FontSize: units.Em(2)}); math.Abs(got.Width-double.Width) > 0.1 {
+		t.Errorf("2em width = %v, want %v", got.Width, double.Width)
+	}
+
+	bold := m.Measure("Hello", Style{FontWeight: FontWeightBold})
+	if bold.Width <= regular.Width {
+		t.Errorf("bold width %v should exceed regular width %v", bold.Width, regular.Width)
+	}
+
+	mono := Style{FontFamily: `"Missing Font", monospace`}
+	if narrow, wide := m.Measure("iii", mono), m.Measure("WWW", mono); narrow.Width != wide.Width {
+		t.Errorf("monospace widths differ: %v and %v", narrow.Width, wide.Width)
+	}
+
+	if got := m.Measure("", Style{}); got.Width != 0 || got.Ascent == 0 {
+		t.Errorf("empty text should have zero width and font metrics, got %+v", got)
+	}
+}
+
+func TestFontMeasurerRegisterFont(t *testing.T) {
+	m := NewFontMeasurer()
+	if err := m.RegisterFont("Code", FontWeightNormal, FontStyleNormal, gomono.TTF); err != nil {
+		t.Fatalf("RegisterFont: %v", err)
+	}
+
+	code := Style{FontFamily: "'Code', sans-serif"}
+	if narrow, wide := m.Measure("iii", code), m.Measure("WWW", code); narrow.Width != wide.Width {
+		t.Error("registered family should be used")
+	}
+
+	if err := m.RegisterFont("Bad", FontWeightNormal, FontStyleNormal, []byte("nope")); err == nil {
+		t.Error("expected error for invalid font data")
+	}
+}
+
+func TestRendererTextMeasurer(t *testing.T) {
+	if NewRenderer(DefaultOptions()).TextMeasurer() != TextMeasurer(DefaultTextMeasurer()) {
+		t.Error("renderer should fall back to the default measurer")
+	}
+
+	opts := DefaultOptions()
+	custom := NewFontMeasurer()
+	opts.TextMeasurer = custom
+	if NewRenderer(opts).TextMeasurer() != TextMeasurer(custom) {
+		t.Error("renderer should use Options.TextMeasurer")
+	}
+}
+
+func TestExportText(t *testing.T) {
+	svgData := `<svg width="100" height="40">
+		<text x="4" y="30" font-size="24" fill="#000000">Hi<tspan dx="4">!</tspan></text>
+	</svg>`
+
+	data, err := Export(svgData, ExportOptions{Format: FormatPNG})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+
+	dark := 0
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r < 0x8000 {
+				dark++
+			}
+		}
+	}
+	if dark == 0 {
+		t.Error("expected text to be drawn")
+	}
+}
+
+func TestExportTextDocumentOrder(t *testing.T) {
+	svgData := `<svg width="100" height="40">
+		<text x="0" y="30" font-size="24" fill="#000000"><tspan fill="#ff0000">W</tspan>W</text>
+	</svg>`
+
+	data, err := Export(svgData, ExportOptions{Format: FormatPNG})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+
+	// The tspan comes first, so the leftmost ink is red
+	bounds := img.Bounds()
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			r, g, _, _ := img.At(x, y).RGBA()
+			if g > 0xc000 {
+				continue // background
+			}
+			if r < g+0x4000 {
+				t.Fatalf("expected red ink at the left, got r=%#x g=%#x at %d,%d", r, g, x, y)
+			}
+			return
+		}
+	}
+	t.Error("expected text to be drawn")
+}
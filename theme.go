@@ -0,0 +1,364 @@
+package svg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/SCKelemen/units"
+)
+
+// Theme is a set of design tokens used to style rendered output
+//
+// A theme generates both a StyleSheet (CSS custom properties and role classes)
+// and Style values for renderer nodes, so switching a whole report between
+// light and dark is a matter of changing Options.Theme.
+type Theme struct {
+	Name         string             `json:"name"`
+	Palette      Palette            `json:"palette"`
+	Typography   Typography         `json:"typography"`
+	Spacing      map[string]float64 `json:"spacing"`      // Named spacing steps in px
+	StrokeWidths map[string]float64 `json:"strokeWidths"` // Named stroke widths in px
+	Radii        map[string]float64 `json:"radii"`        // Named corner radii in px
+}
+
+// Palette holds the colors of a theme
+type Palette struct {
+	Background string `json:"background"`
+	Foreground string `json:"foreground"`
+	Muted      string `json:"muted"`
+	Accent     string `json:"accent"`
+	Border     string `json:"border"`
+
+	// Surfaces are node fills by depth; deeper nodes cycle through the list
+	Surfaces []string `json:"surfaces"`
+
+	// Colors are additional named colors (e.g. "success", "series-1")
+	Colors map[string]string `json:"colors"`
+}
+
+// Typography holds the font tokens of a theme
+type Typography struct {
+	FontFamily     string             `json:"fontFamily"`
+	MonoFontFamily string             `json:"monoFontFamily"`
+	BaseSize       float64            `json:"baseSize"`      // Body text size in px
+	Sizes          map[string]float64 `json:"sizes"`         // Named type scale in px (e.g. "sm", "lg")
+	HeadingSize    float64            `json:"headingSize"`   // Heading text size in px
+	HeadingWeight  FontWeight         `json:"headingWeight"` // Heading font weight
+}
+
+// Role identifies what an element is used for, so a theme can style it
+type Role string
+
+const (
+	RoleNode    Role = "node"    // Layout boxes
+	RoleText    Role = "text"    // Body text
+	RoleHeading Role = "heading" // Titles and headings
+	RoleMuted   Role = "muted"   // Secondary text such as axis labels
+	RoleAccent  Role = "accent"  // Highlighted marks
+	RoleBorder  Role = "border"  // Rules, grid lines and outlines
+)
+
+// ThemeClassPrefix is the prefix of the classes and custom properties in
+// theme stylesheets, so they do not clash with those of the surrounding page
+const ThemeClassPrefix = "theme-"
+
+// themeSelector scopes theme custom properties to SVG roots, so they are not
+// set on an HTML page the SVG is inlined in
+const themeSelector = "svg"
+
+// themeProperty returns the custom property of a theme token, e.g.
+// "--theme-color-background" for "color-background"
+func themeProperty(token string) string {
+	return "--" + ThemeClassPrefix + token
+}
+
+// Class returns the stylesheet class of a role, e.g. "theme-muted"
+func (r Role) Class() string {
+	return ThemeClassPrefix + string(r)
+}
+
+// LightTheme returns the built-in light theme
+func LightTheme() *Theme {
+	return &Theme{
+		Name: "light",
+		Palette: Palette{
+			Background: "#ffffff",
+			Foreground: "#1f2328",
+			Muted:      "#656d76",
+			Accent:     "#0969da",
+			Border:     "#d0d7de",
+			Surfaces:   []string{"#f6f8fa", "#eaeef2", "#dde3e9"},
+		},
+		Typography: defaultTypography(),
+		Spacing:    defaultSpacing(),
+		StrokeWidths: map[string]float64{
+			"thin":    0.5,
+			"default": 1,
+			"thick":   2,
+		},
+		Radii: map[string]float64{
+			"none":    0,
+			"sm":      2,
+			"default": 4,
+			"lg":      8,
+		},
+	}
+}
+
+// DarkTheme returns the built-in dark theme
+// It shares the light theme's typography, spacing, stroke widths and radii
+func DarkTheme() *Theme {
+	t := LightTheme()
+	t.Name = "dark"
+	t.Palette = Palette{
+		Background: "#0d1117",
+		Foreground: "#e6edf3",
+		Muted:      "#8d96a0",
+		Accent:     "#4493f8",
+		Border:     "#30363d",
+		Surfaces:   []string{"#161b22", "#21262d", "#292e36"},
+	}
+	return t
+}
+
+func defaultTypography() Typography {
+	return Typography{
+		FontFamily:     `-apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif`,
+		MonoFontFamily: `ui-monospace, SFMono-Regular, "SF Mono", Menlo, Consolas, monospace`,
+		BaseSize:       14,
+		Sizes: map[string]float64{
+			"xs": 10,
+			"sm": 12,
+			"md": 14,
+			"lg": 16,
+			"xl": 20,
+		},
+		HeadingSize:   20,
+		HeadingWeight: FontWeight600,
+	}
+}
+
+func defaultSpacing() map[string]float64 {
+	return map[string]float64{
+		"xs": 2,
+		"sm": 4,
+		"md": 8,
+		"lg": 16,
+		"xl": 32,
+	}
+}
+
+// LoadTheme reads a theme from JSON
+//
+// Tokens missing from the JSON keep the values of LightTheme, so a theme
+// file only needs to list what it changes.
+func LoadTheme(r io.Reader) (*Theme, error) {
+	t := LightTheme()
+	if err := json.NewDecoder(r).Decode(t); err != nil {
+		return nil, fmt.Errorf("failed to decode theme: %w", err)
+	}
+	return t, nil
+}
+
+// Color returns a palette color by name
+// Names are the Palette fields in lower case ("background", "accent", ...)
+// or keys of Palette.Colors
+func (t *Theme) Color(name string) string {
+	switch name {
+	case "background":
+		return t.Palette.Background
+	case "foreground":
+		return t.Palette.Foreground
+	case "muted":
+		return t.Palette.Muted
+	case "accent":
+		return t.Palette.Accent
+	case "border":
+		return t.Palette.Border
+	}
+	return t.Palette.Colors[name]
+}
+
+// Space returns a spacing step by name, or 0 if it is not defined
+func (t *Theme) Space(name string) float64 {
+	return t.Spacing[name]
+}
+
+// StrokeWidth returns a stroke width by name, or 0 if it is not defined
+func (t *Theme) StrokeWidth(name string) float64 {
+	return t.StrokeWidths[name]
+}
+
+// Radius returns a corner radius by name, or 0 if it is not defined
+func (t *Theme) Radius(name string) float64 {
+	return t.Radii[name]
+}
+
+// FontSize returns a named size from the type scale as a px length
+// Unknown names return the base size
+func (t *Theme) FontSize(name string) units.Length {
+	if size, ok := t.Typography.Sizes[name]; ok {
+		return units.Px(size)
+	}
+	return units.Px(t.Typography.BaseSize)
+}
+
+// Surface returns the fill for a node at the given depth
+func (t *Theme) Surface(depth int) string {
+	if len(t.Palette.Surfaces) == 0 {
+		return t.Palette.Background
+	}
+	if depth < 0 {
+		depth = 0
+	}
+	return t.Palette.Surfaces[depth%len(t.Palette.Surfaces)]
+}
+
+// Style returns the style for an element with the given role
+func (t *Theme) Style(role Role) Style {
+	text := Style{
+		Fill:       t.Palette.Foreground,
+		FontFamily: t.Typography.FontFamily,
+		FontSize:   units.Px(t.Typography.BaseSize),
+	}
+
+	switch role {
+	case RoleNode:
+		return t.DepthStyle(0)
+	case RoleText:
+		return text
+	case RoleHeading:
+		text.FontSize = units.Px(t.Typography.HeadingSize)
+		text.FontWeight = t.Typography.HeadingWeight
+		return text
+	case RoleMuted:
+		text.Fill = t.Palette.Muted
+		text.FontSize = t.FontSize("sm")
+		return text
+	case RoleAccent:
+		return Style{Fill: t.Palette.Accent, Stroke: t.Palette.Accent, StrokeWidth: t.StrokeWidth("default")}
+	case RoleBorder:
+		return Style{Fill: "none", Stroke: t.Palette.Border, StrokeWidth: t.StrokeWidth("default")}
+	}
+	return Style{}
+}
+
+// DepthStyle returns the style for a layout node at the given depth
+func (t *Theme) DepthStyle(depth int) Style {
+	return Style{
+		Fill:        t.Surface(depth),
+		Stroke:      t.Palette.Border,
+		StrokeWidth: t.StrokeWidth("default"),
+	}
+}
+
+// StyleSheet generates a stylesheet exposing the theme as CSS
+//
+// An svg rule defines custom properties for every token (--theme-color-*,
+// --theme-font-*, --theme-space-*, ...), followed by one class per
+// role (.theme-text, .theme-heading, ...; see Role.Class) and per type scale
+// step (.theme-text-sm, .theme-text-lg, ...).
+func (t *Theme) StyleSheet() *StyleSheet {
+	root := Rule(themeSelector, t.colorDeclarations()...)
+	root.Declarations = append(root.Declarations, t.tokenDeclarations()...)
+
+	ss := &StyleSheet{}
+	ss.Add(root)
+	ss.Add(t.classRules()...)
+	return ss
+}
+
+// ColorTokens returns the palette as custom property names (without the
+// leading "--") mapped to colors, e.g. "theme-color-background"
+func (t *Theme) ColorTokens() map[string]string {
+	tokens := make(map[string]string)
+	for _, decl := range t.colorDeclarations() {
+		tokens[strings.TrimPrefix(decl.Property, "--")] = decl.Value
+	}
+	return tokens
+}
+
+// colorDeclarations returns custom property declarations for the palette
+func (t *Theme) colorDeclarations() []Declaration {
+	decls := []Declaration{
+		Decl(themeProperty("color-background"), t.Palette.Background),
+		Decl(themeProperty("color-foreground"), t.Palette.Foreground),
+		Decl(themeProperty("color-muted"), t.Palette.Muted),
+		Decl(themeProperty("color-accent"), t.Palette.Accent),
+		Decl(themeProperty("color-border"), t.Palette.Border),
+	}
+	for i, surface := range t.Palette.Surfaces {
+		decls = append(decls, Decl(themeProperty(fmt.Sprintf("color-surface-%d", i)), surface))
+	}
+	for _, name := range sortedKeys(t.Palette.Colors) {
+		decls = append(decls, Decl(themeProperty("color-"+name), t.Palette.Colors[name]))
+	}
+	return decls
+}
+
+// tokenDeclarations returns custom property declarations for the
+// typography, spacing, stroke width and radius tokens
+func (t *Theme) tokenDeclarations() []Declaration {
+	decls := []Declaration{
+		Decl(themeProperty("font-family"), t.Typography.FontFamily),
+		Decl(themeProperty("font-family-mono"), t.Typography.MonoFontFamily),
+	}
+	for _, name := range sortedKeys(t.Typography.Sizes) {
+		decls = append(decls, Decl(themeProperty("font-size-"+name), formatPx(t.Typography.Sizes[name])))
+	}
+	for _, name := range sortedKeys(t.Spacing) {
+		decls = append(decls, Decl(themeProperty("space-"+name), formatPx(t.Spacing[name])))
+	}
+	for _, name := range sortedKeys(t.StrokeWidths) {
+		decls = append(decls, Decl(themeProperty("stroke-"+name), formatPx(t.StrokeWidths[name])))
+	}
+	for _, name := range sortedKeys(t.Radii) {
+		decls = append(decls, Decl(themeProperty("radius-"+name), formatPx(t.Radii[name])))
+	}
+	return decls
+}
+
+// classRules returns the role and type scale classes
+func (t *Theme) classRules() []StyleRule {
+	rules := []StyleRule{
+		Rule("."+RoleText.Class(),
+			Decl("fill", t.Palette.Foreground),
+			Decl("font-family", t.Typography.FontFamily),
+			Decl("font-size", formatPx(t.Typography.BaseSize)),
+		),
+		Rule("."+RoleHeading.Class(),
+			Decl("fill", t.Palette.Foreground),
+			Decl("font-size", formatPx(t.Typography.HeadingSize)),
+			Decl("font-weight", string(t.Typography.HeadingWeight)),
+		),
+		Rule("."+RoleMuted.Class(), Decl("fill", t.Palette.Muted)),
+		Rule("."+RoleAccent.Class(), Decl("fill", t.Palette.Accent)),
+		Rule("."+RoleBorder.Class(),
+			Decl("fill", "none"),
+			Decl("stroke", t.Palette.Border),
+			Decl("stroke-width", formatPx(t.StrokeWidth("default"))),
+		),
+	}
+	for _, name := range sortedKeys(t.Typography.Sizes) {
+		rules = append(rules, Rule("."+RoleText.Class()+"-"+name, Decl("font-size", formatPx(t.Typography.Sizes[name]))))
+	}
+	return rules
+}
+
+// formatPx formats a CSS pixel length
+func formatPx(v float64) string {
+	return formatDimension(v) + "px"
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
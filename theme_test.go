@@ -0,0 +1,140 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestLoadTheme(t *testing.T) {
+	input := `{
+		"name": "brand",
+		"palette": {"accent": "#ff6600", "colors": {"success": "#1a7f37"}},
+		"typography": {"sizes": {"xxl": 28}},
+		"radii": {"default": 6}
+	}`
+
+	theme, err := LoadTheme(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("LoadTheme: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"name", theme.Name, "brand"},
+		{"accent overridden", theme.Color("accent"), "#ff6600"},
+		{"named color", theme.Color("success"), "#1a7f37"},
+		{"foreground kept", theme.Color("foreground"), LightTheme().Palette.Foreground},
+		{"size added", theme.Typography.Sizes["xxl"], 28.0},
+		{"size kept", theme.Typography.Sizes["sm"], 12.0},
+		{"radius overridden", theme.Radius("default"), 6.0},
+		{"spacing kept", theme.Space("md"), 8.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadThemeInvalid(t *testing.T) {
+	if _, err := LoadTheme(strings.NewReader(`{"palette": 1}`)); err == nil {
+		t.Error("expected error for invalid theme JSON")
+	}
+}
+
+func TestThemeStyles(t *testing.T) {
+	theme := LightTheme()
+
+	heading := theme.Style(RoleHeading)
+	if heading.FontWeight != FontWeight600 || heading.FontSize.Value != 20 {
+		t.Errorf("unexpected heading style: %+v", heading)
+	}
+	if got := theme.Style(RoleMuted).Fill; got != theme.Palette.Muted {
+		t.Errorf("muted fill = %q, want %q", got, theme.Palette.Muted)
+	}
+
+	surfaces := theme.Palette.Surfaces
+	for depth := 0; depth < len(surfaces)*2; depth++ {
+		if got, want := theme.DepthStyle(depth).Fill, surfaces[depth%len(surfaces)]; got != want {
+			t.Errorf("depth %d fill = %q, want %q", depth, got, want)
+		}
+	}
+}
+
+func TestThemeStyleSheet(t *testing.T) {
+	css := DarkTheme().StyleSheet().CSS()
+
+	tests := []string{
+		"svg {",
+		"--theme-color-background: #0d1117;",
+		"--theme-color-surface-0: #161b22;",
+		"--theme-font-size-sm: 12px;",
+		"--theme-space-md: 8px;",
+		"--theme-stroke-thick: 2px;",
+		"--theme-radius-lg: 8px;",
+		".theme-heading {",
+		".theme-text-xl {",
+	}
+
+	for _, want := range tests {
+		if !strings.Contains(css, want) {
+			t.Errorf("expected %q in:\n%s", want, css)
+		}
+	}
+
+	if strings.Contains(css, "\n.text {") || strings.Contains(css, "\n.border {") {
+		t.Errorf("expected role classes to be prefixed in:\n%s", css)
+	}
+	if strings.Contains(css, ":root") || strings.Contains(css, " --color-") {
+		t.Errorf("expected custom properties to be prefixed and scoped to svg in:\n%s", css)
+	}
+	if got := RoleMuted.Class(); got != "theme-muted" {
+		t.Errorf("RoleMuted.Class() = %q, want theme-muted", got)
+	}
+
+	if css != DarkTheme().StyleSheet().CSS() {
+		t.Error("theme stylesheet is not deterministic")
+	}
+}
+
+func TestRenderWithTheme(t *testing.T) {
+	root := &layout.Node{
+		Rect: layout.Rect{Width: 100, Height: 100},
+		Children: []*layout.Node{
+			{Rect: layout.Rect{X: 10, Y: 10, Width: 50, Height: 50}},
+		},
+	}
+
+	opts := DefaultOptions()
+	opts.Theme = DarkTheme()
+	output := RenderToSVG(root, opts)
+
+	tests := []string{
+		`fill="#0d1117"`, // background
+		`fill="#161b22"`, // depth 0
+		`fill="#21262d"`, // depth 1
+		`stroke="#30363d"`,
+		"--theme-color-foreground: #e6edf3;",
+	}
+
+	for _, want := range tests {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output", want)
+		}
+	}
+
+	opts.StyleFunc = func(node interface{}, depth int) Style {
+		return Style{Fill: "red"}
+	}
+	if output := RenderToSVG(root, opts); strings.Contains(output, `fill="#161b22"`) {
+		t.Error("StyleFunc should take precedence over the theme")
+	}
+}